@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/handracs2007/advent_of_code_2020_day16/ticket16"
+)
+
+// DefaultBenchTime is the time each step of the bench subcommand is run for at least.
+const DefaultBenchTime = time.Second
+
+// runBench runs the bench subcommand: it times parsing the input, validating its nearby tickets,
+// inferring the ordering and the whole solve, so that optimizations can be compared on the same notes.
+func runBench(args []string) {
+	flags := flag.NewFlagSet("bench", flag.ExitOnError)
+	benchTime := flags.Duration("benchtime", DefaultBenchTime, "time each step is run for at least")
+	format := flags.String("format", "", "input format, one of \"text\", \"json\", \"yaml\" or \"proto\", guessed from the file extension by default")
+	algo := flags.String("algo", string(ticket16.AlgorithmMatching), "how the ordering is inferred: \"matching\", \"elimination\" or \"backtrack\"")
+	parallelism := flags.Int("parallelism", 0, "number of goroutines validating the nearby tickets, 0 for one per CPU")
+	parseCommandFlags(flags, args, false)
+
+	inputPath := defaultInput()
+	switch flags.NArg() {
+	case 0:
+	case 1:
+		inputPath = flags.Arg(0)
+	default:
+		log.Fatalf("bench takes a single input file: %s.", strings.Join(flags.Args(), " "))
+	}
+
+	algorithm, err := ticket16.ParseAlgorithm(*algo)
+	if err != nil {
+		log.Fatalf("%s.", err)
+	}
+
+	notesFormat := inputFormat(ticket16.Format(*format), inputPath)
+
+	// The input is read once, so that the parse step doesn't time the disk.
+	var data []byte
+	err = withInput(inputPath, RemoteOptions{}, func(r io.Reader) error {
+		data, err = io.ReadAll(r)
+		return err
+	})
+	if err != nil {
+		log.Fatalf("Unable to load input. %s.", err)
+	}
+
+	// The steps are checked once, since a benchmark failing midway only tells that it failed.
+	solver := ticket16.NewSolver(ticket16.WithAlgorithm(algorithm), ticket16.WithParallelism(*parallelism))
+	notes, err := ticket16.DecodeNotes(bytes.NewReader(data), notesFormat, ticket16.ParseOptions{})
+	if err != nil {
+		log.Fatalf("Unable to parse input. %s.", err)
+	}
+	validation := solver.Validate(notes)
+	if _, err := solver.SolveNotes(notes); err != nil {
+		log.Fatalf("Unable to solve. %s.", err)
+	}
+
+	// testing.Benchmark reads its run time from the flags of the go test command.
+	testing.Init()
+	if err := flag.Set("test.benchtime", benchTime.String()); err != nil {
+		log.Fatalf("%s.", err)
+	}
+
+	steps := []struct {
+		name      string
+		benchmark func(b *testing.B)
+		tickets   int // tickets is the number of tickets each run handles.
+	}{
+		{"parse", ticket16.ParseBenchmark(data, notesFormat, ticket16.ParseOptions{}), len(notes.NearbyTickets)},
+		{"validate", ticket16.ValidateBenchmark(solver, notes), len(notes.NearbyTickets)},
+		{"order", ticket16.OrderBenchmark(solver, notes.Configs, validation.Valid), len(validation.Valid)},
+		{"solve", ticket16.SolveBenchmark(solver, notes), len(notes.NearbyTickets)},
+	}
+	for _, step := range steps {
+		result := testing.Benchmark(step.benchmark)
+		if result.N == 0 {
+			log.Fatalf("Unable to benchmark the %s step.", step.name)
+		}
+
+		printBench(os.Stdout, step.name, result, step.tickets)
+	}
+}
+
+// printBench writes the number of runs of a step, the average time and allocations of a run and the
+// throughput, in tickets per second and in MB per second when the step reports them, or else the number
+// of tickets of a run.
+func printBench(w io.Writer, step string, result testing.BenchmarkResult, tickets int) {
+	throughput := fmt.Sprintf("%d tickets", tickets)
+	if rate, ok := result.Extra["tickets/s"]; ok {
+		throughput = fmt.Sprintf("%.0f tickets/s", rate)
+	}
+	if result.Bytes > 0 {
+		throughput += fmt.Sprintf(", %.1f MB/s", float64(result.Bytes)*float64(result.N)/result.T.Seconds()/1e6)
+	}
+
+	perRun := time.Duration(result.NsPerOp())
+	fmt.Fprintf(w, "%-8s %8d runs %14s/run %10d allocs/run  %s\n", step, result.N, perRun, result.AllocsPerOp(), throughput)
+}
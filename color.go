@@ -0,0 +1,72 @@
+package main
+
+import (
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/handracs2007/advent_of_code_2020_day16/ticket16"
+)
+
+// NoColorEnv is the environment variable turning the colors off when it isn't empty, see https://no-color.org.
+const NoColorEnv = "NO_COLOR"
+
+// The ANSI escape sequences of the colors.
+const (
+	colorReset     = "\x1b[0m"
+	colorInvalid   = "\x1b[31m"   // colorInvalid paints the invalid values in red.
+	colorWarning   = "\x1b[33m"   // colorWarning paints the warnings in yellow.
+	colorDeparture = "\x1b[1;36m" // colorDeparture paints the departure fields in bold cyan.
+	colorHighlight = "\x1b[1;33m" // colorHighlight paints the fields the last round of the tui placed in bold yellow.
+)
+
+// colorStdout and colorStderr tell whether the standard output and the standard error are painted.
+var colorStdout, colorStderr bool
+
+// setColor paints the standard output and the standard error when they are terminals, unless -no-color or
+// NoColorEnv turns the colors off.
+func setColor(noColor bool) {
+	enabled := !noColor && os.Getenv(NoColorEnv) == ""
+	colorStdout = enabled && isTerminal(os.Stdout)
+	colorStderr = enabled && isTerminal(os.Stderr)
+}
+
+// isTerminal checks whether the file is a terminal rather than a pipe or a regular file.
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	if err != nil {
+		return false
+	}
+
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// paint paints the text in the color when it is written to the standard output and that is painted. The
+// reports written to a file, like with -output, are never painted.
+func paint(w io.Writer, color string, text string) string {
+	if file, ok := w.(*os.File); !ok || file != os.Stdout || !colorStdout {
+		return text
+	}
+
+	return color + text + colorReset
+}
+
+// paintField paints the field when it is a departure field, since these make part 2.
+func paintField(w io.Writer, field string) string {
+	if !strings.HasPrefix(field, ticket16.DeparturePrefix) {
+		return field
+	}
+
+	return paint(w, colorDeparture, field)
+}
+
+// warnf logs a warning, painting its prefix when the standard error is painted.
+func warnf(format string, args ...any) {
+	prefix := "Warning:"
+	if colorStderr {
+		prefix = colorWarning + prefix + colorReset
+	}
+
+	log.Printf(prefix+" "+format, args...)
+}
@@ -0,0 +1,554 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/handracs2007/advent_of_code_2020_day16/ticket16"
+)
+
+// commands maps the focused subcommands to their runners. Each parses its own flags, and takes the
+// context that is done when the command is interrupted.
+var commands = map[string]func(ctx context.Context, args []string){
+	"generate": func(_ context.Context, args []string) { runGenerate(args) },
+	"bench":    func(_ context.Context, args []string) { runBench(args) },
+	"validate": runValidate,
+	"order":    runOrder,
+	"decode":   runDecode,
+	"stats":    runStats,
+	"repl":     runRepl,
+	"tui":      runTUI,
+}
+
+// profileFlags lists the flags that may come before any subcommand, the others belonging to solve.
+var profileFlags = []string{"cpuprofile", "memprofile", "trace", "v", "vv", "no-color", "config"}
+
+// checkCommandFlags checks that only the profiling, verbosity, color and configuration flags were given before the focused subcommand, whose
+// own flags come after it.
+func checkCommandFlags(command string) error {
+	var misplaced []string
+	flag.Visit(func(f *flag.Flag) {
+		for _, name := range profileFlags {
+			if f.Name == name {
+				return
+			}
+		}
+		misplaced = append(misplaced, "-"+f.Name)
+	})
+
+	if len(misplaced) > 0 {
+		return fmt.Errorf("%s must come after %s", strings.Join(misplaced, ", "), command)
+	}
+
+	return nil
+}
+
+// commandUsage returns the usage of a focused subcommand, printing its synopsis, what it does and its
+// flags.
+func commandUsage(flags *flag.FlagSet, synopsis string, description string) func() {
+	return func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s %s\n\n%s\n", os.Args[0], synopsis, description)
+		flags.PrintDefaults()
+	}
+}
+
+// usageError reports a mistake on the command line with the usage, and exits.
+func usageError(usage func(), err error) {
+	log.Printf("%s.", err)
+	usage()
+	os.Exit(2)
+}
+
+// inputFlags holds the flags telling where the notes are and how to read them, shared by the commands
+// reading notes.
+type inputFlags struct {
+	strict              *bool
+	format              *string
+	rulesPath           *string
+	ticketsPath         *string
+	yourTicketHeader    *string
+	nearbyTicketsHeader *string
+	prefixedLiterals    *bool
+	unknownValues       *bool
+	bounds              *string
+	duplicates          *string
+	urlTimeout          *time.Duration
+	urlMaxSize          *int64
+	example             *bool
+	csvHeader           *bool
+	maxLineLength       *int
+	ragged              *string
+	skipMalformed       *bool
+	warnOverlaps        *bool
+
+	// keepSources is set by the commands pointing at the nearby tickets in the input, see
+	// ParseOptions.KeepSources.
+	keepSources bool
+}
+
+// addInputFlags defines the input flags in the set.
+func addInputFlags(flags *flag.FlagSet) *inputFlags {
+	return &inputFlags{
+		strict:              flags.Bool("strict", false, "treat malformed lines, wrong section order and misplaced blank lines as errors"),
+		format:              flags.String("format", "", "input format, one of \"text\", \"json\", \"yaml\", \"proto\" or \"csv\", guessed from the file extension by default"),
+		rulesPath:           flags.String("rules", "", "file holding the rules (and your ticket), required for \"csv\" inputs"),
+		ticketsPath:         flags.String("tickets", "", "file holding the nearby tickets, in the input format"),
+		yourTicketHeader:    flags.String("your-ticket-header", ticket16.YourTicket, "marker starting the section of your ticket, without the colon"),
+		nearbyTicketsHeader: flags.String("nearby-tickets-header", ticket16.NearbyTickets, "marker starting the section of the nearby tickets, without the colon"),
+		prefixedLiterals:    flags.Bool("prefixed-literals", false, "accept hexadecimal (0x1F) and binary (0b1010) values and range bounds"),
+		unknownValues:       flags.Bool("unknown-values", false, "accept ticket values written \"?\" or left empty when they couldn't be read, which match every rule"),
+		bounds:              flags.String("bounds", string(ticket16.BoundsInclusive), "whether the bounds of the ranges are allowed: \"inclusive\", \"exclusive\", \"exclusive-min\" or \"exclusive-max\", the last three also accepting intervals like [1,5)"),
+		duplicates:          flags.String("duplicates", string(ticket16.DuplicateError), "what to do with fields having more than one rule: \"error\", \"merge\" or \"keep-first\""),
+		urlTimeout:          flags.Duration("url-timeout", DefaultURLTimeout, "maximum time spent downloading inputs given as http(s) URLs"),
+		urlMaxSize:          flags.Int64("url-max-size", DefaultURLMaxSize, "maximum size in bytes of inputs given as http(s) URLs, both downloaded and decompressed"),
+		example:             flags.Bool("example", false, "read the sample notes from the puzzle statement instead of an input file"),
+		csvHeader:           flags.Bool("csv-header", false, "the first row of \"csv\" inputs holds the position of each column"),
+		maxLineLength:       flags.Int("max-line-length", 0, "length in bytes of the longest input line accepted, 0 for no limit"),
+		ragged:              flags.String("ragged", string(ticket16.RaggedError), "what to do with the nearby tickets whose number of values differs from your ticket: \"error\" or \"skip\""),
+		skipMalformed:       flags.Bool("skip-malformed", false, "skip the rules and tickets that can't be parsed instead of failing, ignored with -strict"),
+		warnOverlaps:        flags.Bool("warn-overlaps", false, "also warn about the rules allowing some of the same values, besides the duplicate and contained ones"),
+	}
+}
+
+// parseOptions checks the policies given to the input flags and returns the options of the parser.
+func (f *inputFlags) parseOptions() (ticket16.ParseOptions, error) {
+	duplicatePolicy, err := ticket16.ParseDuplicatePolicy(*f.duplicates)
+	if err != nil {
+		return ticket16.ParseOptions{}, err
+	}
+
+	boundPolicy, err := ticket16.ParseBoundPolicy(*f.bounds)
+	if err != nil {
+		return ticket16.ParseOptions{}, err
+	}
+
+	raggedPolicy, err := ticket16.ParseRaggedPolicy(*f.ragged)
+	if err != nil {
+		return ticket16.ParseOptions{}, err
+	}
+
+	return ticket16.ParseOptions{
+		Strict:              *f.strict,
+		YourTicketHeader:    *f.yourTicketHeader,
+		NearbyTicketsHeader: *f.nearbyTicketsHeader,
+		PrefixedLiterals:    *f.prefixedLiterals,
+		Bounds:              boundPolicy,
+		UnknownValues:       *f.unknownValues,
+		Duplicates:          duplicatePolicy,
+		SkipMalformed:       *f.skipMalformed,
+		MaxLineLength:       *f.maxLineLength,
+		Ragged:              raggedPolicy,
+		KeepSources:         f.keepSources,
+	}, nil
+}
+
+// remote returns the limits of the inputs given as URLs.
+func (f *inputFlags) remote() RemoteOptions {
+	return RemoteOptions{Timeout: *f.urlTimeout, MaxSize: *f.urlMaxSize}
+}
+
+// load reads the notes at the path, or the sample notes with -example, and reports their warnings and the
+// problems of their rules.
+func (f *inputFlags) load(path string, opts ticket16.ParseOptions) (*ticket16.Notes, error) {
+	var notes *ticket16.Notes
+	var err error
+	if *f.example {
+		notes, err = ticket16.ParseNotes(strings.NewReader(ticket16.ExampleInput), opts)
+	} else {
+		notes, err = loadNotes(inputOptions{
+			path:        path,
+			rulesPath:   *f.rulesPath,
+			ticketsPath: *f.ticketsPath,
+			format:      ticket16.Format(*f.format),
+			parse:       opts,
+			csv:         ticket16.CSVOptions{Header: *f.csvHeader, PrefixedLiterals: opts.PrefixedLiterals, Ragged: opts.Ragged, UnknownValues: opts.UnknownValues},
+			remote:      f.remote(),
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	f.report(notes)
+	return notes, nil
+}
+
+// report logs what the notes hold with -v, writes their warnings, the lines skipped and the problems of their rules.
+func (f *inputFlags) report(notes *ticket16.Notes) {
+	logNotes(notes)
+
+	for _, warning := range notes.Warnings {
+		warnf("%s.", warning)
+	}
+
+	if notes.Skipped > 0 {
+		log.Printf("Skipped %d line(s).", notes.Skipped)
+	}
+
+	warnRules(notes.Configs, *f.warnOverlaps)
+}
+
+// validationFlags holds the flags telling how the nearby tickets are validated.
+type validationFlags struct {
+	maxInvalidValues *int
+	parallelism      *int
+	progress         *bool
+}
+
+// addValidationFlags defines the validation flags in the set.
+func addValidationFlags(flags *flag.FlagSet) *validationFlags {
+	return &validationFlags{
+		maxInvalidValues: flags.Int("max-invalid-values", 0, "use the nearby tickets holding up to this many invalid values to infer the ordering, leaving out the positions of these values"),
+		parallelism:      flags.Int("parallelism", 0, "number of goroutines validating the nearby tickets, 0 for one per CPU"),
+		progress:         flags.Bool("progress", false, "report the progress of the validation and of the ordering on stderr"),
+	}
+}
+
+// options returns the options of the solver set by the validation flags.
+func (f *validationFlags) options() []ticket16.Option {
+	opts := []ticket16.Option{
+		ticket16.WithMaxInvalidValues(*f.maxInvalidValues),
+		ticket16.WithParallelism(*f.parallelism),
+	}
+	if *f.progress {
+		opts = append(opts, ticket16.WithProgress(progressPrinter(os.Stderr)))
+	}
+
+	return opts
+}
+
+// orderingFlags holds the flags telling how the ordering is inferred.
+type orderingFlags struct {
+	algo          *string
+	myTicket      *string
+	checkMyTicket *string
+	dedup         *bool
+}
+
+// addOrderingFlags defines the ordering flags in the set.
+func addOrderingFlags(flags *flag.FlagSet) *orderingFlags {
+	return &orderingFlags{
+		algo:          flags.String("algo", string(ticket16.AlgorithmMatching), "how the ordering is inferred: \"matching\" falls back to a bipartite matching when \"elimination\" alone gets stuck, \"backtrack\" searches exhaustively"),
+		myTicket:      flags.String("my-ticket", string(ticket16.MyTicketInclude), "whether your ticket is used to infer the ordering: \"include\", \"exclude\" or \"validate\" to include it only when valid"),
+		checkMyTicket: flags.String("check-my-ticket", string(ticket16.MyTicketCheckWarn), "what to do when your ticket holds values matching no rule: \"warn\", \"error\" or \"off\""),
+		dedup:         flags.Bool("dedup", false, "collapse the identical tickets before inferring the ordering, and report how many there were"),
+	}
+}
+
+// options checks the policies given to the ordering flags and returns the options of the solver.
+func (f *orderingFlags) options() ([]ticket16.Option, error) {
+	algorithm, err := ticket16.ParseAlgorithm(*f.algo)
+	if err != nil {
+		return nil, err
+	}
+
+	myTicketPolicy, err := ticket16.ParseMyTicketPolicy(*f.myTicket)
+	if err != nil {
+		return nil, err
+	}
+
+	myTicketCheck, err := ticket16.ParseMyTicketCheck(*f.checkMyTicket)
+	if err != nil {
+		return nil, err
+	}
+
+	return []ticket16.Option{
+		ticket16.WithAlgorithm(algorithm),
+		ticket16.WithMyTicket(myTicketPolicy),
+		ticket16.WithMyTicketCheck(myTicketCheck),
+		ticket16.WithDedup(*f.dedup),
+		ticket16.WithRoundTrace(roundTrace()),
+	}, nil
+}
+
+// readInput loads the notes of the input of a focused subcommand reading notes, once its flags are parsed.
+// The mistakes on the command line are usage errors. It returns the options of the parser along the notes.
+func readInput(flags *flag.FlagSet, input *inputFlags) (*ticket16.Notes, ticket16.ParseOptions) {
+	path, err := inputPath(flags.Args())
+	if err != nil {
+		usageError(flags.Usage, err)
+	}
+
+	return loadInput(flags, input, path)
+}
+
+// loadInput is like readInput, with the path of the input already known.
+func loadInput(flags *flag.FlagSet, input *inputFlags, path string) (*ticket16.Notes, ticket16.ParseOptions) {
+	opts, err := input.parseOptions()
+	if err != nil {
+		usageError(flags.Usage, err)
+	}
+
+	notes, err := input.load(path, opts)
+	if err != nil {
+		log.Fatalf("Unable to load input. %s.", err)
+	}
+
+	return notes, opts
+}
+
+// runValidate runs the validate subcommand: it screens the nearby tickets and prints part 1, the sum of
+// their values matching no rule.
+func runValidate(ctx context.Context, args []string) {
+	flags := flag.NewFlagSet("validate", flag.ExitOnError)
+	input := addInputFlags(flags)
+	validation := addValidationFlags(flags)
+	explain := flags.Bool("explain", false, "also list the invalid values of each invalid ticket, with the rules closest to matching them")
+	flags.Usage = commandUsage(flags, "validate [validate flags] [input file]", "Screens the nearby tickets and prints part 1, the sum of their values matching no rule.")
+
+	parseCommandFlags(flags, args, true)
+	input.keepSources = *explain
+	notes, opts := readInput(flags, input)
+
+	solver := ticket16.NewSolver(append(validation.options(), ticket16.WithParseOptions(opts))...)
+	screened, err := solver.ValidateContext(ctx, notes)
+	if err != nil {
+		log.Fatalf("Unable to validate the tickets. %s.", err)
+	}
+
+	fmt.Fprintf(os.Stdout, "part 1: %d\n", screened.ErrorRate)
+	if screened.ErrorRateOverflow {
+		warnf("part 1 overflows 64-bit integers, solve the notes with -bigint for the right answer.")
+	}
+	if len(screened.Tolerated) > 0 {
+		fmt.Fprintf(os.Stdout, "tickets: %d valid, %d tolerated, %d invalid\n", len(screened.Valid), len(screened.Tolerated), len(screened.Invalid))
+	} else {
+		fmt.Fprintf(os.Stdout, "tickets: %d valid, %d invalid\n", len(screened.Valid), len(screened.Invalid))
+	}
+
+	if *explain {
+		nearby := append(screened.Invalid, screened.Tolerated...)
+		sort.SliceStable(nearby, func(i, j int) bool { return nearby[i].Index < nearby[j].Index })
+		printInvalid(os.Stdout, "invalid", nearby, notes.Sources)
+	}
+}
+
+// printInvalid writes the invalid values of each nearby ticket under the label, with the line of the
+// ticket and its text when the sources of the tickets are known.
+func printInvalid(w io.Writer, label string, nearby []ticket16.InvalidTicket, sources []ticket16.TicketSource) {
+	for _, ticket := range nearby {
+		values := make([]string, len(ticket.Values))
+		for idx, invalid := range ticket.Values {
+			values[idx] = paint(w, colorInvalid, invalid.String())
+		}
+
+		if ticket.Index < len(sources) {
+			source := sources[ticket.Index]
+			fmt.Fprintf(w, "%s: nearby ticket %d, line %d %q, %s\n", label, ticket.Index, source.Line, source.Text, strings.Join(values, "; "))
+			continue
+		}
+
+		fmt.Fprintf(w, "%s: nearby ticket %d, %s\n", label, ticket.Index, strings.Join(values, "; "))
+	}
+}
+
+// solveInput parses the flags of a focused subcommand inferring the ordering, and solves its input.
+func solveInput(ctx context.Context, flags *flag.FlagSet, args []string, input *inputFlags, validation *validationFlags, ordering *orderingFlags) (*ticket16.Notes, ticket16.Result) {
+	parseCommandFlags(flags, args, true)
+	notes, opts := readInput(flags, input)
+	_, result := solveNotes(ctx, flags, notes, opts, validation, ordering)
+
+	return notes, result
+}
+
+// solveNotes solves the notes of a focused subcommand with the solver its flags describe, and the extra
+// options. It returns the solver along the result, for the commands asking it more.
+func solveNotes(ctx context.Context, flags *flag.FlagSet, notes *ticket16.Notes, opts ticket16.ParseOptions, validation *validationFlags, ordering *orderingFlags, extra ...ticket16.Option) (*ticket16.Solver, ticket16.Result) {
+	solver := newCommandSolver(flags, opts, validation, ordering, extra...)
+	result, err := solver.SolveNotesContext(ctx, notes)
+	if err != nil {
+		log.Fatalf("Unable to solve. %s.", err)
+	}
+	logSummary(result)
+	warnResult(result)
+
+	return solver, result
+}
+
+// newCommandSolver returns the solver the flags of a focused subcommand describe, with the extra options.
+func newCommandSolver(flags *flag.FlagSet, opts ticket16.ParseOptions, validation *validationFlags, ordering *orderingFlags, extra ...ticket16.Option) *ticket16.Solver {
+	orderingOpts, err := ordering.options()
+	if err != nil {
+		usageError(flags.Usage, err)
+	}
+
+	solverOpts := append(validation.options(), orderingOpts...)
+	solverOpts = append(solverOpts, ticket16.WithParseOptions(opts))
+	solverOpts = append(solverOpts, extra...)
+
+	return ticket16.NewSolver(solverOpts...)
+}
+
+// runOrder runs the order subcommand: it prints the field held by each position of the tickets.
+func runOrder(ctx context.Context, args []string) {
+	flags := flag.NewFlagSet("order", flag.ExitOnError)
+	input := addInputFlags(flags)
+	validation := addValidationFlags(flags)
+	ordering := addOrderingFlags(flags)
+	candidates := flags.Bool("candidates", false, "also list the fields each position could hold before the elimination")
+	dot := flags.Bool("dot", false, "write the graph of the fields and of the positions they could hold in the DOT language of Graphviz instead, with the field found at each position highlighted, even when none could be found")
+	flags.Usage = commandUsage(flags, "order [order flags] [input file]", "Infers the field held by each position of the tickets and prints it, telling the fields that were picked among several.")
+
+	parseCommandFlags(flags, args, true)
+	notes, opts := readInput(flags, input)
+	if *dot {
+		writeCandidateGraph(ctx, os.Stdout, notes, newCommandSolver(flags, opts, validation, ordering))
+		return
+	}
+
+	_, result := solveNotes(ctx, flags, notes, opts, validation, ordering)
+	printPositions(os.Stdout, result.Positions, *candidates)
+}
+
+// writeCandidateGraph writes the candidate graph of the notes in DOT, for order -dot. When the notes can't
+// be solved, the graph is still written, with the positions and the fields left unmatched painted red,
+// before failing with the error.
+func writeCandidateGraph(ctx context.Context, w io.Writer, notes *ticket16.Notes, solver *ticket16.Solver) {
+	matrix, err := solver.NotesCandidateMatrixContext(ctx, notes)
+	if err != nil {
+		log.Fatalf("Unable to compute the candidates. %s.", err)
+	}
+
+	graph := ticket16.CandidateGraph{Matrix: matrix}
+	result, solveErr := solver.SolveNotesContext(ctx, notes)
+	if solveErr == nil {
+		graph.Positions = result.Positions
+	}
+	errors.As(solveErr, &graph.Unsolvable)
+
+	if err := ticket16.WriteDOT(w, graph); err != nil {
+		log.Fatalf("Unable to write the graph. %s.", err)
+	}
+
+	if solveErr != nil {
+		log.Fatalf("Unable to solve. %s.", solveErr)
+	}
+	logSummary(result)
+	warnResult(result)
+}
+
+// printPositions writes the field of each position, telling when it was picked among several, and with
+// candidates the fields the position could hold.
+func printPositions(w io.Writer, positions []ticket16.PositionReport, candidates bool) {
+	for _, position := range positions {
+		line := fmt.Sprintf("position %d: %s", position.Position, paintField(w, position.Field))
+		if !position.Forced {
+			line += " (picked among several)"
+		}
+		if candidates {
+			line += fmt.Sprintf(", candidates %s", strings.Join(position.Candidates, ", "))
+		}
+
+		fmt.Fprintln(w, line)
+	}
+}
+
+// runDecode runs the decode subcommand: it translates your ticket, and the nearby tickets when asked to,
+// into the value of each field.
+func runDecode(ctx context.Context, args []string) {
+	flags := flag.NewFlagSet("decode", flag.ExitOnError)
+	input := addInputFlags(flags)
+	validation := addValidationFlags(flags)
+	ordering := addOrderingFlags(flags)
+	nearby := flags.Bool("nearby", false, "also decode the nearby tickets, the invalid ones included")
+	mine := flags.Bool("mine", false, "write your ticket as a table of its fields and their values, aligned")
+	flags.Usage = commandUsage(flags, "decode [decode flags] [input file]", "Infers the ordering, then writes the value of each field of your ticket, and of the nearby tickets with -nearby.")
+
+	notes, result := solveInput(ctx, flags, args, input, validation, ordering)
+
+	if *mine {
+		printTable(os.Stdout, "your ticket", notes.MyTicket, result.Ordering)
+	} else {
+		printDecoded(os.Stdout, "your ticket", notes.MyTicket, result.Ordering)
+	}
+	if *nearby {
+		for idx, ticket := range notes.NearbyTickets {
+			fmt.Fprintln(os.Stdout)
+			printDecoded(os.Stdout, fmt.Sprintf("nearby ticket %d", idx), ticket, result.Ordering)
+		}
+	}
+}
+
+// printDecoded writes the value of each field of the ticket under the name of the ticket, in the order of
+// the ticket. The unknown values are written "?", and the values past the ordering are left out.
+func printDecoded(w io.Writer, name string, ticket ticket16.Ticket, ordering []string) {
+	fmt.Fprintf(w, "%s:\n", name)
+	for fieldPos, field := range ordering {
+		if fieldPos >= len(ticket.Values) {
+			break
+		}
+
+		if !ticket.Known(fieldPos) {
+			fmt.Fprintf(w, "%s: ?\n", paintField(w, field))
+			continue
+		}
+
+		fmt.Fprintf(w, "%s: %d\n", paintField(w, field), ticket.Values[fieldPos])
+	}
+}
+
+// printTable writes the ticket under its name as a table of the field at each position and its value, the
+// values aligned past the longest field. The unknown values are written "?", and the values past the
+// ordering are left out.
+func printTable(w io.Writer, name string, ticket ticket16.Ticket, ordering []string) {
+	width := 0
+	for _, field := range ordering {
+		if length := utf8.RuneCountInString(field); length > width {
+			width = length
+		}
+	}
+
+	fmt.Fprintf(w, "%s:\n", name)
+	for fieldPos, field := range ordering {
+		if fieldPos >= len(ticket.Values) {
+			break
+		}
+
+		value := "?"
+		if ticket.Known(fieldPos) {
+			value = strconv.FormatInt(ticket.Values[fieldPos], 10)
+		}
+
+		padding := strings.Repeat(" ", width-utf8.RuneCountInString(field))
+		fmt.Fprintf(w, "  %s%s  %s\n", paintField(w, field), padding, value)
+	}
+}
+
+// runStats runs the stats subcommand: it prints the statistics of the values of the valid nearby tickets
+// at each position, and the coverage of the rules when asked to.
+func runStats(ctx context.Context, args []string) {
+	flags := flag.NewFlagSet("stats", flag.ExitOnError)
+	input := addInputFlags(flags)
+	validation := addValidationFlags(flags)
+	bins := flags.Int("bins", ticket16.DefaultHistogramBins, "number of bins of the histograms")
+	coverage := flags.Bool("coverage", false, "also write how many values of the nearby tickets each rule matches, the dead rules, and the gaps between the rules the invalid values fall in")
+	flags.Usage = commandUsage(flags, "stats [stats flags] [input file]", "Prints the statistics of the values of the valid nearby tickets at each position, to see why an ordering can't be found.")
+
+	parseCommandFlags(flags, args, true)
+	if *bins < 1 {
+		usageError(flags.Usage, fmt.Errorf("-bins must be at least 1"))
+	}
+
+	notes, opts := readInput(flags, input)
+
+	solver := ticket16.NewSolver(append(validation.options(), ticket16.WithParseOptions(opts))...)
+	screened, err := solver.ValidateContext(ctx, notes)
+	if err != nil {
+		log.Fatalf("Unable to validate the tickets. %s.", err)
+	}
+
+	printStats(os.Stdout, ticket16.ValueStats(screened.Valid, *bins))
+	if *coverage {
+		printCoverage(os.Stdout, ticket16.AnalyzeCoverage(notes.Configs, notes.NearbyTickets))
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// The completion scripts ask the program itself for the flags of the command being completed, reading
+// them from its usage, so that they never lag behind the flags. Only the commands are listed in the
+// scripts, since running the program with anything else than a command could start a solve.
+var completionTemplates = map[string]*template.Template{
+	"bash": template.Must(template.New("bash").Parse(bashCompletion)),
+	"zsh":  template.Must(template.New("zsh").Parse("#compdef {{.Program}}\n\nautoload -U +X bashcompinit && bashcompinit\n\n" + bashCompletion)),
+	"fish": template.Must(template.New("fish").Parse(fishCompletion)),
+}
+
+// bashCompletion completes the commands and the flags, and the files everywhere else.
+const bashCompletion = `_{{.Function}}() {
+	local cur=${COMP_WORDS[COMP_CWORD]}
+	local command= word
+	for word in "${COMP_WORDS[@]:1:COMP_CWORD-1}"; do
+		case " {{.Commands}} " in
+		*" $word "*) command=$word; break ;;
+		esac
+	done
+	case $command in
+	solve|fetch) command= ;;
+	esac
+
+	if [[ $cur == -* ]]; then
+		local flags=$("${COMP_WORDS[0]}" $command -h 2>&1 | sed -n 's/^  \(-[^ ]*\).*/\1/p')
+		COMPREPLY=($(compgen -W "$flags" -- "$cur"))
+	elif [[ -z $command ]]; then
+		COMPREPLY=($(compgen -W "{{.Commands}}" -- "$cur"))
+	fi
+}
+
+complete -o default -F _{{.Function}} {{.Program}}
+`
+
+// fishCompletion completes the commands and the flags, and the files everywhere else.
+const fishCompletion = `function __{{.Function}}_command
+	set -l words (commandline -opc)
+	for word in $words[2..-1]
+		if contains -- $word {{.Commands}}
+			echo $word
+			return
+		end
+	end
+end
+
+function __{{.Function}}_flags
+	set -l words (commandline -opc)
+	set -l command (__{{.Function}}_command)
+	if contains -- "$command" solve fetch
+		set command
+	end
+	$words[1] $command -h 2>&1 | string replace -r -f '^  (-\S+).*' '$1'
+end
+
+complete -c {{.Program}} -n 'test -z (__{{.Function}}_command)' -a '{{.Commands}}'
+complete -c {{.Program}} -n 'string match -q -- "-*" (commandline -ct)' -a '(__{{.Function}}_flags)'
+`
+
+// nonIdentifier matches what the name of the program can't hold in the name of a shell function.
+var nonIdentifier = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+func init() {
+	// The completion lists the commands, it can't be part of their initialization.
+	commands["completion"] = runCompletion
+}
+
+// runCompletion runs the completion subcommand: it writes the completion script of the shell, covering the
+// commands, their flags and the input files.
+func runCompletion(_ context.Context, args []string) {
+	flags := flag.NewFlagSet("completion", flag.ExitOnError)
+	flags.Usage = commandUsage(flags, "completion bash|zsh|fish", "Writes the completion script of the shell, e.g. for bash:\n\n  source <("+os.Args[0]+" completion bash)\n")
+
+	parseCommandFlags(flags, args, false)
+	if flags.NArg() != 1 {
+		usageError(flags.Usage, fmt.Errorf("completion takes the name of the shell"))
+	}
+
+	script, ok := completionTemplates[flags.Arg(0)]
+	if !ok {
+		usageError(flags.Usage, fmt.Errorf("unknown shell %q", flags.Arg(0)))
+	}
+
+	names := []string{"solve", "fetch"}
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	program := filepath.Base(os.Args[0])
+	err := script.Execute(os.Stdout, struct{ Program, Function, Commands string }{
+		Program:  program,
+		Function: nonIdentifier.ReplaceAllString(program, "_"),
+		Commands: strings.Join(names, " "),
+	})
+	if err != nil {
+		log.Fatalf("Unable to write the completion. %s.", err)
+	}
+}
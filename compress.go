@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Compression identifies the compression format of an input.
+type Compression int
+
+const (
+	// NoCompression means the input is plain text.
+	NoCompression Compression = iota
+	// Gzip means the input is gzip compressed.
+	Gzip
+	// Zstd means the input is zstandard compressed.
+	Zstd
+)
+
+// gzipMagic and zstdMagic are the bytes every gzip and zstandard stream starts with.
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// compressionFromPath guesses the compression from the file extension of the path.
+// It returns NoCompression when the extension is not a known compressed one.
+func compressionFromPath(path string) Compression {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".gz", ".gzip":
+		return Gzip
+	case ".zst", ".zstd":
+		return Zstd
+	default:
+		return NoCompression
+	}
+}
+
+// compressionFromMagic guesses the compression from the first bytes of the input.
+func compressionFromMagic(header []byte) Compression {
+	switch {
+	case bytes.HasPrefix(header, gzipMagic):
+		return Gzip
+	case bytes.HasPrefix(header, zstdMagic):
+		return Zstd
+	default:
+		return NoCompression
+	}
+}
+
+// decompressedReader closes both the decompressor and the underlying input.
+type decompressedReader struct {
+	io.Reader
+	closeDecoder func() error
+	input        io.Closer
+}
+
+// Close implements the io.Closer interface.
+func (d *decompressedReader) Close() error {
+	decoderErr := d.closeDecoder()
+	inputErr := d.input.Close()
+
+	if decoderErr != nil {
+		return decoderErr
+	}
+
+	return inputErr
+}
+
+// decompress wraps the input so that it is decompressed on the fly. The compression is taken from the
+// extension of the path when it has a known one, otherwise it is detected from the magic bytes.
+// Plain text inputs are returned as they are. Closing the returned reader closes the input too.
+func decompress(path string, input io.ReadCloser) (io.ReadCloser, error) {
+	buffered := bufio.NewReader(input)
+
+	compression := compressionFromPath(path)
+	if compression == NoCompression {
+		// Peek fails on inputs shorter than the magic, in which case they can't be compressed anyway.
+		header, _ := buffered.Peek(len(zstdMagic))
+		compression = compressionFromMagic(header)
+	}
+
+	switch compression {
+	case Gzip:
+		gzipReader, err := gzip.NewReader(buffered)
+		if err != nil {
+			return nil, err
+		}
+
+		return &decompressedReader{Reader: gzipReader, closeDecoder: gzipReader.Close, input: input}, nil
+	case Zstd:
+		zstdReader, err := zstd.NewReader(buffered)
+		if err != nil {
+			return nil, err
+		}
+
+		closeDecoder := func() error {
+			zstdReader.Close()
+			return nil
+		}
+
+		return &decompressedReader{Reader: zstdReader, closeDecoder: closeDecoder, input: input}, nil
+	default:
+		return &decompressedReader{Reader: buffered, closeDecoder: func() error { return nil }, input: input}, nil
+	}
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// configName is the path of the configuration file, inside the user configuration directory.
+const configName = "ticket16/config.yaml"
+
+// configFile holds the defaults of the flags read from the configuration file, e.g.:
+//
+//	input: /home/me/aoc/day16.txt
+//	session-file: /home/me/.config/aoc/session
+//	json: true
+//	group: [departure, arrival]
+//	parallelism: 4
+//	commands:
+//	  tui:
+//	    delay: 200ms
+//
+// The keys are the names of the flags, with the values they would be given on the command line, lists for
+// the flags given several times. The top-level ones apply to solve and fetch, and to the commands reading
+// notes that have the same flag. The ones of a command only apply to it. The flags given on the command
+// line always win, also over the -json or -stream of the configuration they conflict with.
+type configFile struct {
+	// Input is the input file used when none is given on the command line, instead of DefaultInputPath.
+	Input string `yaml:"input"`
+
+	Flags    map[string]any            `yaml:",inline"`
+	Commands map[string]map[string]any `yaml:"commands"`
+
+	// path is the file the configuration was read from, to point at it in the errors.
+	path string
+}
+
+// config is the configuration loaded by main, empty when there is none.
+var config = &configFile{}
+
+// DefaultConfigPath returns the path of the configuration file read by default, inside the configuration
+// directory of the user (e.g. ~/.config/ticket16/config.yaml on Linux). It is empty when there is no such
+// directory.
+func DefaultConfigPath() string {
+	userConfigDir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(userConfigDir, configName)
+}
+
+// loadConfig reads the configuration file at the path. A missing file is only an error when required, as
+// when the path was given on the command line. The keys naming no flag are rejected once applied, see
+// applyConfig.
+func loadConfig(path string, required bool) (*configFile, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) && !required {
+		return &configFile{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	loaded := &configFile{path: path}
+	if err := yaml.NewDecoder(file).Decode(loaded); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if _, ok := loaded.Flags["config"]; ok {
+		return nil, fmt.Errorf("%s: the configuration can't point at another one", path)
+	}
+	for name := range loaded.Commands {
+		if _, ok := commands[name]; !ok {
+			return nil, fmt.Errorf("%s: unknown command %q", path, name)
+		}
+	}
+
+	return loaded, nil
+}
+
+// defaultInput returns the input file used when none is given on the command line.
+func defaultInput() string {
+	if config.Input != "" {
+		return config.Input
+	}
+
+	return DefaultInputPath
+}
+
+// applyConfig gives the flags of the set that weren't on the command line their value in the configuration,
+// and returns the names of the flags it set, to tell them from the ones of the command line, see
+// resolveConflicts. Every value must have a flag in the set. The values come from the section of the
+// configuration, e.g. "commands.tui.", which the errors prefix the keys with.
+func applyConfig(flags *flag.FlagSet, values map[string]any, section string) (map[string]bool, error) {
+	given := map[string]bool{}
+	flags.Visit(func(f *flag.Flag) {
+		given[f.Name] = true
+	})
+
+	// The names are sorted so that the same mistake always gets the same error.
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	configured := map[string]bool{}
+	for _, name := range names {
+		f := flags.Lookup(name)
+		if f == nil {
+			return nil, fmt.Errorf("%s: unknown key %q", config.path, section+name)
+		}
+		if given[name] {
+			continue
+		}
+
+		items, ok := values[name].([]any)
+		if !ok {
+			items = []any{values[name]}
+		} else if _, ok := f.Value.(*stringList); !ok {
+			return nil, fmt.Errorf("%s: %q takes a single value", config.path, section+name)
+		}
+
+		for _, item := range items {
+			if _, ok := item.(map[string]any); ok {
+				return nil, fmt.Errorf("%s: %q takes a value, not a mapping", config.path, section+name)
+			}
+
+			if err := f.Value.Set(fmt.Sprint(item)); err != nil {
+				return nil, fmt.Errorf("%s: invalid value %v for %q: %w", config.path, item, section+name, err)
+			}
+		}
+		configured[name] = true
+	}
+
+	return configured, nil
+}
+
+// resolveConflicts checks that the flag of a mode, like -json, isn't combined with the flags it conflicts
+// with, once the configuration is applied. The command line wins over the configuration: the mode is turned
+// off when the configuration turns it on and the command line gives a conflicting flag, and the
+// conflicting flags of the configuration are reset when the command line turns the mode on. The conflicts
+// coming from the same place are an error.
+func resolveConflicts(flags *flag.FlagSet, mode string, conflicts []string, configured map[string]bool) error {
+	// A flag is given when it doesn't hold its default, so that e.g. "time: false" never conflicts.
+	given := func(f *flag.Flag) bool {
+		return f.Value.String() != f.DefValue
+	}
+
+	modeFlag := flags.Lookup(mode)
+	if !given(modeFlag) {
+		return nil
+	}
+
+	var same, overridden []*flag.Flag
+	for _, name := range conflicts {
+		f := flags.Lookup(name)
+		if f == nil || !given(f) {
+			continue
+		}
+
+		if configured[name] == configured[mode] {
+			same = append(same, f)
+		} else {
+			overridden = append(overridden, f)
+		}
+	}
+
+	if configured[mode] && len(overridden) > 0 {
+		debugf("-%s of the configuration is turned off by -%s.", mode, overridden[0].Name)
+		return modeFlag.Value.Set(modeFlag.DefValue)
+	}
+
+	for _, f := range overridden {
+		debugf("-%s of the configuration is turned off by -%s.", f.Name, mode)
+		if err := f.Value.Set(f.DefValue); err != nil {
+			return err
+		}
+	}
+
+	if len(same) > 0 {
+		names := make([]string, len(same))
+		for idx, f := range same {
+			names[idx] = "-" + f.Name
+		}
+		return fmt.Errorf("-%s can't be combined with %s", mode, strings.Join(names, ", "))
+	}
+
+	return nil
+}
+
+// parseCommandFlags parses the flags of a focused subcommand, and gives the ones that weren't on the
+// command line their value in the configuration: the one of the command, or the top-level one when shared
+// with the flags of solve.
+func parseCommandFlags(flags *flag.FlagSet, args []string, shared bool) {
+	flags.Parse(args)
+
+	section := config.Commands[flags.Name()]
+	values := map[string]any{}
+	if shared {
+		for name, value := range config.Flags {
+			if _, ok := section[name]; !ok && flags.Lookup(name) != nil {
+				values[name] = value
+			}
+		}
+	}
+
+	if _, err := applyConfig(flags, values, ""); err != nil {
+		log.Fatalf("Unable to apply the configuration. %s.", err)
+	}
+	if _, err := applyConfig(flags, section, "commands."+flags.Name()+"."); err != nil {
+		log.Fatalf("Unable to apply the configuration. %s.", err)
+	}
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Year and Day identify the puzzle on adventofcode.com.
+const (
+	Year = 2020
+	Day  = 16
+)
+
+// InputURL is the address of the puzzle input on adventofcode.com.
+const InputURL = "https://adventofcode.com/2020/day/16/input"
+
+// cacheName is the name of the directory holding the downloaded inputs, inside the user cache directory.
+const cacheName = "ticket16"
+
+// SessionEnv is the environment variable holding the adventofcode.com session cookie when it is not
+// given on the command line.
+const SessionEnv = "AOC_SESSION"
+
+// userAgent identifies the requests made to adventofcode.com, as its maintainer asks automated tools to do.
+const userAgent = "github.com/handracs2007/advent_of_code_2020_day16"
+
+// errNoSession tells that the session cookie needed to download the input is missing.
+var errNoSession = fmt.Errorf("no adventofcode.com session cookie, set it with -session or %s", SessionEnv)
+
+// fetchTimeout bounds the time spent downloading the puzzle input.
+const fetchTimeout = 30 * time.Second
+
+// FetchInput downloads the puzzle input of the user owning the given session cookie. The session is the
+// value of the "session" cookie set by adventofcode.com after logging in.
+// The caller is responsible for closing the returned reader.
+func FetchInput(session string) (io.ReadCloser, error) {
+	return fetchInput(&http.Client{Timeout: fetchTimeout}, InputURL, session)
+}
+
+// DefaultCacheDir returns the directory where the downloaded inputs are stored by default, inside the
+// cache directory of the user (e.g. ~/.cache/ticket16 on Linux).
+func DefaultCacheDir() (string, error) {
+	userCacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(userCacheDir, cacheName), nil
+}
+
+// cachePath returns the path of the cached input of the user owning the session. The user is identified
+// by a hash of the session, so that the session itself never ends up on disk.
+func cachePath(cacheDir string, session string) string {
+	hash := sha256.Sum256([]byte(strings.TrimSpace(session)))
+	user := hex.EncodeToString(hash[:8])
+
+	return filepath.Join(cacheDir, strconv.Itoa(Year), strconv.Itoa(Day), user+".txt")
+}
+
+// FetchInputCached returns the puzzle input of the user owning the session from the cache directory,
+// downloading it with FetchInput only when it is not cached yet, or when refresh is true.
+// The caller is responsible for closing the returned reader.
+func FetchInputCached(session string, cacheDir string, refresh bool) (io.ReadCloser, error) {
+	if strings.TrimSpace(session) == "" {
+		return nil, errNoSession
+	}
+	path := cachePath(cacheDir, session)
+
+	if !refresh {
+		cached, err := os.Open(path)
+		if err == nil {
+			return cached, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+
+	input, err := FetchInput(session)
+	if err != nil {
+		return nil, err
+	}
+	defer input.Close()
+
+	// The cache is private, like the session it was downloaded with.
+	if err := writeAtomic(path, input, 0o600); err != nil {
+		return nil, err
+	}
+
+	return os.Open(path)
+}
+
+// writeAtomic stores the content at the given path. The content is written to a temporary file first and
+// then renamed, so that an interrupted download never leaves a truncated input in the cache, nor a failed
+// solve a truncated report or state. A new file gets the permissions, less the umask, and a replaced one
+// keeps its own. The missing directories get the permissions too, searchable where readable.
+func writeAtomic(path string, input io.Reader, perm fs.FileMode) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, perm|(perm>>2)&0o111); err != nil {
+		return err
+	}
+
+	tmp, err := createTemp(dir, perm)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // Fails harmlessly once renamed.
+
+	if info, err := os.Stat(path); err == nil {
+		if err := tmp.Chmod(info.Mode().Perm()); err != nil {
+			tmp.Close()
+			return err
+		}
+	}
+
+	if _, err := io.Copy(tmp, input); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// createTemp creates a new temporary file in the directory, like os.CreateTemp, but with the permissions
+// instead of 0600.
+func createTemp(dir string, perm fs.FileMode) (*os.File, error) {
+	var suffix [8]byte
+	for {
+		if _, err := rand.Read(suffix[:]); err != nil {
+			return nil, err
+		}
+
+		tmp, err := os.OpenFile(filepath.Join(dir, ".write-"+hex.EncodeToString(suffix[:])), os.O_RDWR|os.O_CREATE|os.O_EXCL, perm)
+		if !errors.Is(err, fs.ErrExist) {
+			return tmp, err
+		}
+	}
+}
+
+// fetchInput downloads the puzzle input at the given URL with the given client.
+func fetchInput(client *http.Client, url string, session string) (io.ReadCloser, error) {
+	session = strings.TrimSpace(session)
+	if session == "" {
+		return nil, errNoSession
+	}
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	req.AddCookie(&http.Cookie{Name: "session", Value: session})
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		// adventofcode.com explains the problem (expired session, puzzle not unlocked...) in the body.
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		resp.Body.Close()
+
+		return nil, fmt.Errorf("unable to download %s: %s: %s", url, resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	return resp.Body, nil
+}
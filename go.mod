@@ -0,0 +1,9 @@
+module github.com/handracs2007/advent_of_code_2020_day16
+
+go 1.23
+
+require (
+	github.com/klauspost/compress v1.18.0
+	google.golang.org/protobuf v1.36.9
+	gopkg.in/yaml.v3 v3.0.1
+)
@@ -2,258 +2,1066 @@
 package main
 
 import (
-	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
+	"io/fs"
 	"log"
 	"os"
-	"strconv"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"sort"
 	"strings"
+	"time"
+
+	"github.com/handracs2007/advent_of_code_2020_day16/ticket16"
 )
 
-// YourTicket defines the indicator telling that the content after this is your Ticket details.
-const YourTicket = "your ticket"
+// DefaultInputPath defines the input file used when no path is given on the command line.
+const DefaultInputPath = "input.txt"
+
+// StdinPath defines the input path telling that the notes should be read from the standard input.
+const StdinPath = "-"
+
+// usage prints the command line help to stderr.
+func usage() {
+	fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] [solve] [flags] [input file]\n", os.Args[0])
+	fmt.Fprintf(flag.CommandLine.Output(), "       %s [flags] fetch [flags]\n", os.Args[0])
+	fmt.Fprintf(flag.CommandLine.Output(), "       %s validate|order|decode|stats [command flags] [input file]\n", os.Args[0])
+	fmt.Fprintf(flag.CommandLine.Output(), "       %s repl [repl flags] [input file]\n", os.Args[0])
+	fmt.Fprintf(flag.CommandLine.Output(), "       %s tui [tui flags] [input file]\n", os.Args[0])
+	fmt.Fprintf(flag.CommandLine.Output(), "       %s generate [generate flags]\n", os.Args[0])
+	fmt.Fprintf(flag.CommandLine.Output(), "       %s bench [bench flags] [input file]\n", os.Args[0])
+	fmt.Fprintf(flag.CommandLine.Output(), "       %s completion bash|zsh|fish\n\n", os.Args[0])
+	fmt.Fprintf(flag.CommandLine.Output(), "Solves both parts of the puzzle. The input file defaults to %q.\n", DefaultInputPath)
+	fmt.Fprintf(flag.CommandLine.Output(), "Use %q, or pipe the notes without giving a file, to read from the standard input.\n", StdinPath)
+	fmt.Fprintf(flag.CommandLine.Output(), "Input files may also be http(s) URLs, which are downloaded on the fly.\n")
+	fmt.Fprintf(flag.CommandLine.Output(), "The fetch command downloads your input from adventofcode.com before solving it.\n")
+	fmt.Fprintf(flag.CommandLine.Output(), "The validate, order, decode and stats commands only run a step of the solve, with the flags it needs,\n")
+	fmt.Fprintf(flag.CommandLine.Output(), "see \"%s validate -h\" and the like: part 1, the ordering, the fields of the tickets and the\n", os.Args[0])
+	fmt.Fprintf(flag.CommandLine.Output(), "statistics of their values. Only the profiling, verbosity, color and configuration flags may come before them.\n")
+	fmt.Fprintf(flag.CommandLine.Output(), "The repl command solves the input, then answers questions about it, see \"%s repl -h\".\n", os.Args[0])
+	fmt.Fprintf(flag.CommandLine.Output(), "The tui command draws the rounds of the elimination narrowing the candidates, see \"%s tui -h\".\n", os.Args[0])
+	fmt.Fprintf(flag.CommandLine.Output(), "The generate command writes synthetic notes, see \"%s generate -h\".\n", os.Args[0])
+	fmt.Fprintf(flag.CommandLine.Output(), "The bench command times the steps of solving the input, see \"%s bench -h\".\n", os.Args[0])
+	fmt.Fprintf(flag.CommandLine.Output(), "The completion command writes the completion script of a shell, see \"%s completion -h\".\n", os.Args[0])
+	fmt.Fprintf(flag.CommandLine.Output(), "\nThe configuration file holds the defaults of the flags, which those of the command line override, conflicting or not:\n\n")
+	fmt.Fprintf(flag.CommandLine.Output(), "  input: day16.txt       # the input file used when none is given\n")
+	fmt.Fprintf(flag.CommandLine.Output(), "  json: true             # the flags of solve and fetch, also applied to the commands having them\n")
+	fmt.Fprintf(flag.CommandLine.Output(), "  group: [departure, arrival]\n")
+	fmt.Fprintf(flag.CommandLine.Output(), "  commands:              # the flags of a single command\n")
+	fmt.Fprintf(flag.CommandLine.Output(), "    tui: {delay: 200ms}\n\n")
+	flag.PrintDefaults()
+}
+
+// parseArgs parses the command line arguments. It returns the subcommand and the path of the input file.
+// The "solve" subcommand is optional, so both "ticket16 solve notes.txt" and "ticket16 notes.txt" work.
+// The "fetch" subcommand takes no input file. Flags may be given before or after the subcommand.
+func parseArgs(args []string) (string, string, error) {
+	command := "solve"
+	if len(args) > 0 && (args[0] == "solve" || args[0] == "fetch") {
+		command = args[0]
+
+		if err := flag.CommandLine.Parse(args[1:]); err != nil {
+			return "", "", err
+		}
+		args = flag.Args()
+	}
+
+	if command == "fetch" {
+		if len(args) > 0 {
+			return "", "", fmt.Errorf("fetch takes no arguments: %s", strings.Join(args, " "))
+		}
+
+		return command, "", nil
+	}
+
+	path, err := inputPath(args)
+	if err != nil {
+		return "", "", err
+	}
+
+	return command, path, nil
+}
+
+// inputPath returns the path of the input file given by the positional arguments of a command: the
+// standard input when it is piped and no file is given, the input of the configuration or DefaultInputPath
+// otherwise.
+func inputPath(args []string) (string, error) {
+	switch len(args) {
+	case 0:
+		if stdinIsPipe() {
+			return StdinPath, nil
+		}
+
+		return defaultInput(), nil
+	case 1:
+		return args[0], nil
+	default:
+		return "", fmt.Errorf("too many arguments: %s", strings.Join(args, " "))
+	}
+}
+
+// stdinIsPipe checks whether the standard input is redirected from a pipe or a file instead of a terminal.
+func stdinIsPipe() bool {
+	info, err := os.Stdin.Stat()
+	if err != nil {
+		return false
+	}
+
+	return info.Mode()&os.ModeCharDevice == 0
+}
+
+// openInput opens the input at the given path. The StdinPath opens the standard input instead of a file,
+// and HTTP(S) URLs are downloaded within the limits of the remote options.
+// Gzip and zstandard compressed inputs are decompressed on the fly, the size limit of the URLs applying to
+// the decompressed document as well.
+// The caller is responsible for closing the returned reader.
+func openInput(path string, remote RemoteOptions) (io.ReadCloser, error) {
+	var input io.ReadCloser
+
+	if path == StdinPath {
+		input = io.NopCloser(os.Stdin)
+	} else if isURL(path) {
+		body, err := OpenURL(path, remote)
+		if err != nil {
+			return nil, err
+		}
+		input = body
+	} else {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		input = file
+	}
+
+	decompressed, err := decompress(path, input)
+	if err != nil {
+		input.Close()
+		return nil, err
+	}
+
+	if isURL(path) {
+		return LimitDocument(decompressed, path, remote), nil
+	}
+
+	return decompressed, nil
+}
+
+// inputOptions groups the command line options telling where the notes are and how to read them.
+type inputOptions struct {
+	path        string          // path is the positional input, holding the whole notes by default.
+	rulesPath   string          // rulesPath is the optional rules document, whose format comes from its extension.
+	ticketsPath string          // ticketsPath is the optional nearby tickets dump.
+	format      ticket16.Format // format is the one of the input and of the tickets dump, guessed from their extension when empty.
+	parse       ticket16.ParseOptions
+	csv         ticket16.CSVOptions
+	remote      RemoteOptions
+}
+
+// FormatFromPath guesses the format from the file extension of the path, ignoring the extension of a
+// compressed file. It returns FormatText when the extension is not known.
+func FormatFromPath(path string) ticket16.Format {
+	if compressionFromPath(path) != NoCompression {
+		path = strings.TrimSuffix(path, filepath.Ext(path))
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return ticket16.FormatJSON
+	case ".yaml", ".yml":
+		return ticket16.FormatYAML
+	case ".pb", ".proto", ".binpb":
+		return ticket16.FormatProto
+	case ".csv":
+		return ticket16.FormatCSV
+	default:
+		return ticket16.FormatText
+	}
+}
+
+// inputFormat returns the format given on the command line, or the one guessed from the path when none
+// was given.
+func inputFormat(format ticket16.Format, path string) ticket16.Format {
+	if format == "" {
+		return FormatFromPath(path)
+	}
+
+	return format
+}
+
+// loadNotes reads the notes. Without rules or tickets files, the whole notes come from the input.
+// Otherwise the rules (and your ticket) come from the rules file, or the input when not given, and the
+// nearby tickets come from the tickets file, or the input when not given.
+func loadNotes(opts inputOptions) (*ticket16.Notes, error) {
+	var notes *ticket16.Notes
+	var err error
+
+	if opts.rulesPath == "" && opts.ticketsPath == "" {
+		format := inputFormat(opts.format, opts.path)
+		if format == ticket16.FormatCSV {
+			return nil, fmt.Errorf("%q input requires a rules file", ticket16.FormatCSV)
+		}
+
+		err = withInput(opts.path, opts.remote, func(r io.Reader) error {
+			notes, err = ticket16.DecodeNotes(r, format, opts.parse)
+			return err
+		})
+
+		return notes, err
+	}
+
+	rulesPath, rulesFormat := opts.rulesPath, FormatFromPath(opts.rulesPath)
+	if rulesPath == "" {
+		rulesPath, rulesFormat = opts.path, inputFormat(opts.format, opts.path)
+	}
+
+	ticketsPath := opts.ticketsPath
+	if ticketsPath == "" {
+		ticketsPath = opts.path
+	}
+	ticketsFormat := inputFormat(opts.format, ticketsPath)
+
+	if rulesPath == StdinPath && ticketsPath == StdinPath {
+		return nil, fmt.Errorf("the rules and the tickets can't both be read from the standard input")
+	}
+
+	err = withInput(rulesPath, opts.remote, func(r io.Reader) error {
+		notes, err = ticket16.DecodeRules(r, rulesFormat, opts.parse)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var tickets []ticket16.Ticket
+	var warnings []*ticket16.ParseError
+	err = withInput(ticketsPath, opts.remote, func(r io.Reader) error {
+		if ticketsFormat == ticket16.FormatCSV {
+			tickets, err = ticket16.DecodeTicketsCSV(r, opts.csv)
+		} else {
+			tickets, warnings, err = ticket16.DecodeTickets(r, ticketsFormat, opts.parse)
+		}
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The text ticket dumps only warn about the lines they skip.
+	notes = notes.WithTickets(tickets)
+	if ticketsFormat == ticket16.FormatText {
+		notes.Skipped += len(warnings)
+	}
+	notes.Warnings = append(notes.Warnings, warnings...)
+
+	return notes, nil
+}
+
+// runGenerate runs the generate subcommand: it writes synthetic notes to stdout, and their known answers
+// to stderr so that they don't mix with the notes.
+func runGenerate(args []string) {
+	flags := flag.NewFlagSet("generate", flag.ExitOnError)
+	fields := flags.Int("fields", 20, "number of fields")
+	tickets := flags.Int("tickets", 240, "number of nearby tickets")
+	invalidRate := flags.Float64("invalid-rate", 0.25, "fraction of nearby tickets having an invalid value")
+	seed := flags.Int64("seed", time.Now().UnixNano(), "seed of the random generator, to reproduce an output")
+	parseCommandFlags(flags, args, false)
+
+	if flags.NArg() > 0 {
+		log.Fatalf("generate takes no arguments: %s.", strings.Join(flags.Args(), " "))
+	}
+
+	generated, err := ticket16.GenerateNotes(ticket16.GenerateOptions{
+		Fields:      *fields,
+		Tickets:     *tickets,
+		InvalidRate: *invalidRate,
+		Seed:        *seed,
+	})
+	if err != nil {
+		log.Fatalf("Unable to generate notes. %s.", err)
+	}
+
+	if err := ticket16.WriteNotes(os.Stdout, generated.Notes); err != nil {
+		log.Fatalf("Unable to write notes. %s.", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "seed: %d\n", *seed)
+	fmt.Fprintf(os.Stderr, "part 1: %d\n", generated.Part1)
+	fmt.Fprintf(os.Stderr, "part 2: %d\n", generated.Part2)
+	fmt.Fprintf(os.Stderr, "ordering: %s\n", strings.Join(generated.Ordering, ", "))
+}
+
+// fetchNotes downloads the puzzle input from adventofcode.com, or takes it from the cache directory, and
+// parses it. The default cache directory is used when cacheDir is empty.
+func fetchNotes(session string, cacheDir string, refresh bool, opts ticket16.ParseOptions) (*ticket16.Notes, error) {
+	if cacheDir == "" {
+		var err error
+		if cacheDir, err = DefaultCacheDir(); err != nil {
+			return nil, err
+		}
+	}
+
+	input, err := FetchInputCached(session, cacheDir, refresh)
+	if err != nil {
+		return nil, err
+	}
+	defer input.Close()
+
+	notes, err := ticket16.ParseNotes(input, opts)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", InputURL, err)
+	}
+
+	return notes, nil
+}
+
+// withInput opens the input at the given path, calls decode with it and closes it.
+// Decoding errors are prefixed with the path.
+func withInput(path string, remote RemoteOptions, decode func(r io.Reader) error) error {
+	// Let's open the input
+	input, err := openInput(path, remote)
+	if err != nil {
+		return err
+	}
+	defer input.Close() // Close the input
 
-// NearbyTickets defines the indicator telling that the content after this is the nearby tickets details.
-const NearbyTickets = "nearby tickets"
+	if err := decode(input); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
 
-// ValidRange stores the valid range (minimum and maximum Values). Both inclusive.
-type ValidRange struct {
-	Min int
-	Max int
+	return nil
 }
 
-// Ticket stores the Ticket details.
-type Ticket struct {
-	Values []int
+// printResult writes the answers to both parts of the puzzle and the product of each group, followed by
+// the number of nearby tickets found valid and invalid, and tolerated when some were, then the number of
+// duplicates when they were collapsed.
+func printResult(w io.Writer, result ticket16.Result) {
+	fmt.Fprintf(w, "part 1: %s\n", result.Part1Answer())
+	fmt.Fprintf(w, "part 2: %s\n", result.Part2Answer())
+
+	for _, group := range result.Groups {
+		fmt.Fprintf(w, "group %s: %s\n", strings.TrimSpace(group.Prefix), result.GroupAnswer(group))
+	}
+	if result.ToleratedTickets > 0 {
+		fmt.Fprintf(w, "tickets: %d valid, %d tolerated, %d invalid\n", result.ValidTickets, result.ToleratedTickets, result.InvalidTickets)
+	} else {
+		fmt.Fprintf(w, "tickets: %d valid, %d invalid\n", result.ValidTickets, result.InvalidTickets)
+	}
+	if result.Duplicates != nil {
+		fmt.Fprintf(w, "duplicates: %s\n", result.Duplicates)
+	}
 }
 
-// Configuration stores the Ticket Configuration.
-type Configuration struct {
-	Field  string
-	Ranges []ValidRange
+// writeResult writes the result in text with printResult, or as a single JSON object with -json, for the
+// scripts that would rather not scrape the text.
+func writeResult(w io.Writer, result ticket16.Result, asJSON bool) {
+	if !asJSON {
+		printResult(w, result)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		log.Fatalf("Unable to write the result. %s.", err)
+	}
 }
 
-// parseConfiguration parses the Configuration string. It returns the Configuration object.
-// We assume that the config string is always valid.
-func parseConfiguration(config string) Configuration {
-	// Format is <Field>: <range> [or <range]...
-	// Get the Field first.
-	colonIdx := strings.Index(config, ":")
-	field := config[:colonIdx]
+// jsonConflicts lists the flags writing more text after the result, which would break the JSON of -json.
+var jsonConflicts = []string{"orderings", "coverage", "stats", "repairs", "throughput", "time", "mine", "positions", "discarded"}
 
-	// Get the range string, by removing everything before the range indicator.
-	config = config[colonIdx+2:]
+// checkJSONFlags checks that -json isn't combined with the flags in jsonConflicts, see resolveConflicts.
+func checkJSONFlags(configured map[string]bool) error {
+	return resolveConflicts(flag.CommandLine, "json", jsonConflicts, configured)
+}
 
-	// Separate the range by " or " separator.
-	ranges := strings.Split(config, " or ")
+// printThroughput writes how fast the tickets were parsed and validated, the rounds of the elimination and
+// the peak memory, to see the effect of the parallel options. The parse time is zero when unknown, like
+// with -stream, whose validation includes reading the notes. The peak memory is the memory the runtime
+// obtained from the system, which it never gives back in this count.
+func printThroughput(w io.Writer, result ticket16.Result, parse time.Duration) {
+	tickets := result.ValidTickets + result.ToleratedTickets + result.InvalidTickets
+	values := tickets * len(result.Ordering)
 
-	// Build the ValidRange for each Ranges.
-	validRanges := make([]ValidRange, len(ranges))
+	if parse > 0 {
+		fmt.Fprintf(w, "throughput parse: %d tickets in %s, %.0f tickets/s\n", tickets, parse.Round(time.Microsecond), perSecond(tickets, parse))
+	}
+	fmt.Fprintf(w, "throughput validate: %d values in %s, %.0f values/s\n", values, result.Timings.Validate.Round(time.Microsecond), perSecond(values, result.Timings.Validate))
+	fmt.Fprintf(w, "throughput order: %d rounds in %s\n", result.Rounds, result.Timings.Order.Round(time.Microsecond))
 
-	for idx, rng := range ranges {
-		// Separate the range by "-" to get the minimum and maximum value.
-		minMax := strings.Split(rng, "-")
-		min, _ := strconv.Atoi(minMax[0]) // We assume that the value is always a valid integer value,hence ignoring the error handling. Don't do this in production.
-		max, _ := strconv.Atoi(minMax[1]) // We assume that the value is always a valid integer value,hence ignoring the error handling. Don't do this in production.
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	fmt.Fprintf(w, "throughput memory: %.1f MB peak\n", float64(mem.Sys)/1e6)
+}
 
-		validRanges[idx] = ValidRange{Min: min, Max: max}
+// printTimings writes the wall time spent in each step of the solve, to see where the time goes before
+// profiling. The parse time is zero when unknown, like with -stream, whose validation includes reading the
+// notes.
+func printTimings(w io.Writer, timings ticket16.Timings) {
+	if timings.Parse > 0 {
+		fmt.Fprintf(w, "time parse: %s\n", timings.Parse.Round(time.Microsecond))
 	}
+	fmt.Fprintf(w, "time validate: %s\n", timings.Validate.Round(time.Microsecond))
+	fmt.Fprintf(w, "time order: %s\n", timings.Order.Round(time.Microsecond))
+	fmt.Fprintf(w, "time total: %s\n", (timings.Parse + timings.Validate + timings.Order).Round(time.Microsecond))
+}
 
-	return Configuration{
-		Field:  field,
-		Ranges: validRanges,
+// perSecond returns the rate of n items handled in the duration, 0 when it is too short to tell.
+func perSecond(n int, d time.Duration) float64 {
+	if d <= 0 {
+		return 0
 	}
+
+	return float64(n) / d.Seconds()
 }
 
-// parseTicket parses the Ticket string. It returns a Ticket object that contains
-// all the Values found inside the Ticket.
-// We assume that the Ticket data is always valid.
-func parseTicket(ticketData string) Ticket {
-	data := strings.Split(ticketData, ",")
-	values := make([]int, len(data))
+// stringList is a flag.Value collecting the values of a flag given several times.
+type stringList []string
 
-	for idx, datum := range data {
-		values[idx], _ = strconv.Atoi(datum) // We assume that the value is always a valid integer value, hence ignoring the error handling. Don't do this in production.
+// String implements the flag.Value interface.
+func (l *stringList) String() string {
+	return strings.Join(*l, ", ")
+}
+
+// Set implements the flag.Value interface.
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// printOrderings writes the orderings consistent with the tickets, one per line, telling when there may be
+// more of them than the limit.
+func printOrderings(w io.Writer, orderings [][]string, limit int) {
+	more := len(orderings) > limit
+	if more {
+		orderings = orderings[:limit]
+	}
+
+	fmt.Fprintf(w, "orderings: %d\n", len(orderings))
+	for idx, ordering := range orderings {
+		fmt.Fprintf(w, "ordering %d: %s\n", idx+1, strings.Join(ordering, ", "))
 	}
 
-	return Ticket{Values: values}
+	if more {
+		fmt.Fprintf(w, "more orderings left out, raise -orderings to see them\n")
+	}
 }
 
-// isValidTicket checks whether a given Ticket is valid or not based on the set of configurations.
-// It returns 2 Values. First value is either true (if the Ticket is valid) or false if the Ticket is
-// invalid. When the Ticket is invalid, the second return value should contain list of invalid Values,
-// otherwise, it will be nil.
-func isValidTicket(ticket Ticket, configs []Configuration) (bool, []int) {
-	invalidValues := make([]int, 0)
+// Metrics lists the breakdowns of part 1 that -metrics can select.
+var Metrics = []string{"discarded", "rules", "positions"}
+
+// parseMetrics checks the comma-separated breakdowns given to -metrics, "all" selecting every one of them.
+func parseMetrics(list string) (map[string]bool, error) {
+	selected := map[string]bool{}
+	if list == "" {
+		return selected, nil
+	}
 
-	for _, value := range ticket.Values {
-		foundValid := false
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
 
-		for _, config := range configs {
-			// Now we have the value and a config, let's check against it.
-			for _, minMax := range config.Ranges {
-				if value >= minMax.Min && value <= minMax.Max {
-					// The value is valid
-					foundValid = true
-				}
+		known := false
+		for _, metric := range Metrics {
+			if name == metric || name == "all" {
+				selected[metric], known = true, true
 			}
 		}
 
-		if !foundValid {
-			invalidValues = append(invalidValues, value)
+		if !known {
+			return nil, fmt.Errorf("unknown metric %q", name)
 		}
 	}
 
-	if len(invalidValues) > 0 {
-		return false, invalidValues
+	return selected, nil
+}
+
+// printMetrics writes the breakdowns of part 1 selected with -metrics.
+func printMetrics(w io.Writer, metrics *ticket16.ErrorMetrics, selected map[string]bool) {
+	if selected["discarded"] {
+		fmt.Fprintf(w, "discarded: %d\n", metrics.Discarded)
 	}
 
-	return true, nil
+	if selected["rules"] {
+		for _, rule := range metrics.Rules {
+			fmt.Fprintf(w, "rule %s: %d accepted\n", rule.Field, rule.Accepted)
+		}
+	}
+
+	if selected["positions"] {
+		for _, position := range metrics.Positions {
+			fmt.Fprintf(w, "invalid at position %d: %d, summing to %d\n", position.Position, position.Invalid, position.Sum)
+		}
+	}
 }
 
-// getOrdering gets the ordering of the fields in the ticket.
-func getOrdering(tickets []Ticket, configs []Configuration) []string {
-	fieldSize := len(tickets[0].Values)
-	orderedFields := make([]string, fieldSize)
+// sparks are the bars drawing the histograms of -stats, from the lowest to the highest. Empty bins are blank.
+var sparks = []rune("▁▂▃▄▅▆▇█")
 
-	for len(configs) > 0 {
-		// We process from the first position, second position, and so on.
-		for fieldPos := 0; fieldPos < fieldSize; fieldPos++ {
-			// Get all the values of the given position in all tickets.
-			values := make([]int, 0)
-			for _, ticket := range tickets {
-				values = append(values, ticket.Values[fieldPos])
+// printStats writes the statistics of the values seen at each position of the tickets, with their histogram
+// drawn as a sparkline.
+func printStats(w io.Writer, stats []ticket16.PositionStats) {
+	for _, position := range stats {
+		if position.Count == 0 {
+			fmt.Fprintf(w, "stats position %d: no value\n", position.Position)
+			continue
+		}
+
+		highest := 0
+		for _, count := range position.Histogram {
+			if count > highest {
+				highest = count
 			}
+		}
 
-			// We now check the validity of all those values against the configurations.
-			// All must pass to be considered that the values belong to a field.
-			validConfigCount := 0
-			validConfig := Configuration{}
-			validConfigIdx := -1
-			for idx, config := range configs {
-				isValidConfig := true
-
-				for _, value := range values {
-					// Now we have the value and a config, let's check against it.
-					isValidRange := false
-					for _, allowedRange := range config.Ranges {
-						if value >= allowedRange.Min && value <= allowedRange.Max {
-							// The value is valid
-							isValidRange = true
-							break
-						}
-					}
-
-					if !isValidRange {
-						isValidConfig = false
-						break
-					}
-				}
-
-				if isValidConfig {
-					validConfigCount++
-
-					validConfig = config
-					validConfigIdx = idx
-
-					if validConfigCount > 1 {
-						// If the value has more than one valid config, let's skip first.
-						// We must find configuration that is really unique.
-						break
-					}
-				}
+		histogram := make([]rune, len(position.Histogram))
+		for bin, count := range position.Histogram {
+			histogram[bin] = ' '
+			if count > 0 {
+				histogram[bin] = sparks[count*(len(sparks)-1)/highest]
 			}
+		}
 
-			if validConfigCount == 1 {
-				// The values fulfill a specific configuration.
-				orderedFields[fieldPos] = validConfig.Field
+		fmt.Fprintf(w, "stats position %d: %d value(s), min %d, max %d, mean %.1f, median %g, histogram %s\n",
+			position.Position, position.Count, position.Min, position.Max, position.Mean, position.Median, string(histogram))
+	}
+}
 
-				// We remove this config from the list of configurations so that
-				// its not being checked in further iteration.
-				configs = append(configs[:validConfigIdx], configs[validConfigIdx+1:]...)
-			}
+// printCoverage writes which rules the values of the nearby tickets match, and the gaps between the rules
+// their invalid values fall in. The gaps holding no value are left out.
+func printCoverage(w io.Writer, coverage ticket16.Coverage) {
+	for _, rule := range coverage.Rules {
+		fmt.Fprintf(w, "coverage rule %s: %d value(s)\n", rule.Field, rule.Accepted)
+	}
+
+	if len(coverage.Dead) > 0 {
+		fmt.Fprintf(w, "coverage dead rules: %s\n", strings.Join(coverage.Dead, ", "))
+	}
+
+	for _, gap := range coverage.Gaps {
+		if gap.Invalid > 0 {
+			fmt.Fprintf(w, "coverage %s\n", gap)
+		}
+	}
+}
+
+// printRepairs prints the repairs suggested for each invalid value of your ticket and of the nearby tickets.
+func printRepairs(w io.Writer, mine []ticket16.InvalidValue, nearby []ticket16.InvalidTicket, configs []ticket16.Configuration) {
+	report := func(ticket string, invalid ticket16.InvalidValue) {
+		repairs := ticket16.SuggestRepairs(invalid.Value, configs)
+		if len(repairs) == 0 {
+			fmt.Fprintf(w, "repair: %s, position %d: %s, no suggestion\n", ticket, invalid.Position, paint(w, colorInvalid, fmt.Sprint(invalid.Value)))
+			return
+		}
+
+		suggestions := make([]string, len(repairs))
+		for idx, repair := range repairs {
+			suggestions[idx] = repair.String()
 		}
+		fmt.Fprintf(w, "repair: %s, position %d: %s → %s\n", ticket, invalid.Position, paint(w, colorInvalid, fmt.Sprint(invalid.Value)), strings.Join(suggestions, " or "))
 	}
 
-	return orderedFields
+	for _, invalid := range mine {
+		report("your ticket", invalid)
+	}
+
+	for _, ticket := range nearby {
+		for _, invalid := range ticket.Values {
+			report(fmt.Sprintf("nearby ticket %d", ticket.Index), invalid)
+		}
+	}
+}
+
+// streamConflicts lists the flags that -stream can't honor, since they need all the tickets in memory.
+var streamConflicts = []string{"rules", "tickets", "example", "csv-header", "orderings", "coverage", "stats", "repairs", "metrics", "dedup", "discarded", "report"}
+
+// checkStreamFlags checks that the command line can be solved with -stream, which only reads the text
+// notes of an input file. A -stream of the configuration is turned off for the commands and inputs it
+// can't solve, like the conflicting flags of the command line turn it off, see resolveConflicts.
+func checkStreamFlags(command string, format ticket16.Format, configured map[string]bool) error {
+	streamFlag := flag.Lookup("stream")
+	if streamFlag.Value.String() == streamFlag.DefValue {
+		return nil
+	}
+
+	if configured["stream"] && (command == "fetch" || format != ticket16.FormatText) {
+		debugf("-stream of the configuration is turned off for this input.")
+		return streamFlag.Value.Set(streamFlag.DefValue)
+	}
+
+	if command == "fetch" {
+		return fmt.Errorf("-stream can't be used with fetch")
+	}
+
+	if format != ticket16.FormatText {
+		return fmt.Errorf("-stream only reads %q inputs", ticket16.FormatText)
+	}
+
+	return resolveConflicts(flag.CommandLine, "stream", streamConflicts, configured)
+}
+
+// runStream solves the input with -stream, without keeping the tickets in memory, and returns the result
+// and your ticket. The rules are checked like with the other inputs. With a state path, the solve resumes
+// from the checkpoint of the previous one, and the checkpoint of this one replaces it.
+func runStream(ctx context.Context, solver *ticket16.Solver, path string, remote RemoteOptions, warnOverlaps bool, statePath string) (ticket16.Result, ticket16.Ticket) {
+	skipped := 0
+	var myTicket ticket16.Ticket
+	handler := ticket16.NotesHandler{
+		Configurations: func(configs []ticket16.Configuration) error {
+			warnRules(configs, warnOverlaps)
+			return nil
+		},
+		MyTicket: func(ticket ticket16.Ticket) error {
+			myTicket = ticket
+			return nil
+		},
+		Warning: func(warning *ticket16.ParseError) {
+			warnf("%s.", warning)
+		},
+		Skipped: func(warning *ticket16.ParseError) {
+			warnf("%s.", warning)
+			skipped++
+		},
+	}
+
+	var resume *ticket16.Checkpoint
+	if statePath != "" {
+		resume = loadCheckpoint(statePath)
+	}
+
+	var result ticket16.Result
+	var checkpoint *ticket16.Checkpoint
+	solve := func(resume *ticket16.Checkpoint) error {
+		skipped = 0
+		return withInput(path, remote, func(r io.Reader) error {
+			var err error
+			result, checkpoint, err = solver.ResumeStream(ctx, r, handler, resume)
+			return err
+		})
+	}
+
+	err := solve(resume)
+	if errors.Is(err, ticket16.ErrCheckpointMismatch) && path != StdinPath {
+		warnf("%s, solving from scratch.", err)
+		resume = nil
+		err = solve(nil)
+	}
+	if err != nil {
+		log.Fatalf("Unable to solve. %s.", err)
+	}
+	logSummary(result)
+
+	if skipped > 0 {
+		log.Printf("Skipped %d line(s).", skipped)
+	}
+
+	if statePath != "" {
+		if resume != nil && resume.Rules == checkpoint.Rules {
+			log.Printf("Resumed after %d nearby ticket(s).", resume.Tickets)
+		}
+
+		if err := saveCheckpoint(statePath, checkpoint); err != nil {
+			warnf("unable to save the state. %s.", err)
+		}
+	}
+
+	warnResult(result)
+
+	return result, myTicket
+}
+
+// loadCheckpoint reads the checkpoint saved at the path by a previous -stream solve. There is none when the
+// file doesn't exist yet, or when it can't be read, which is only worth a warning.
+func loadCheckpoint(path string) *ticket16.Checkpoint {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	checkpoint := &ticket16.Checkpoint{}
+	if err == nil {
+		err = json.Unmarshal(data, checkpoint)
+	}
+	if err != nil {
+		warnf("unable to load the state, solving from scratch. %s.", err)
+		return nil
+	}
+
+	return checkpoint
+}
+
+// saveCheckpoint writes the checkpoint to the path, replacing the previous one at once, see writeAtomic.
+func saveCheckpoint(path string, checkpoint *ticket16.Checkpoint) error {
+	data, err := json.Marshal(checkpoint)
+	if err != nil {
+		return err
+	}
+
+	return writeAtomic(path, bytes.NewReader(data), 0o644)
+}
+
+// progressPrinter returns a progress callback rewriting a single line of the writer for each step, only
+// when its percentage changes. The steps whose total isn't known show the count instead.
+func progressPrinter(w io.Writer) func(progress ticket16.Progress) {
+	var step ticket16.ProgressStep
+	percent := -1
+	open := false
+
+	return func(progress ticket16.Progress) {
+		if progress.Step != step && open {
+			fmt.Fprintln(w)
+			open = false
+		}
+
+		if progress.Total == 0 {
+			fmt.Fprintf(w, "\r%s: %d", progress.Step, progress.Done)
+			step, open = progress.Step, true
+			return
+		}
+
+		current := progress.Done * 100 / progress.Total
+		if progress.Step == step && current == percent {
+			return
+		}
+		step, percent = progress.Step, current
+
+		fmt.Fprintf(w, "\r%s: %3d%%", progress.Step, current)
+		open = progress.Done < progress.Total
+		if !open {
+			fmt.Fprintln(w)
+		}
+	}
+}
+
+// warnRules logs the rules that may keep the ordering from being found: the duplicate and contained ones,
+// and the overlapping ones when asked, since they rarely do.
+func warnRules(configs []ticket16.Configuration, warnOverlaps bool) {
+	issues := ticket16.AnalyzeRules(configs)
+	if !warnOverlaps {
+		issues = ticket16.RedundantRules(issues)
+	}
+	for _, issue := range issues {
+		warnf("%s.", issue)
+	}
+}
+
+// warnResult logs what makes the answers of the result doubtful.
+func warnResult(result ticket16.Result) {
+	if result.RaggedTickets > 0 {
+		log.Printf("Skipped %d ragged ticket(s).", result.RaggedTickets)
+	}
+
+	for _, invalid := range result.MyTicketInvalid {
+		warnf("your ticket is invalid, part 2 may be wrong: %s.", invalid)
+	}
+
+	for _, violation := range result.Violations {
+		warnf("%s.", violation)
+	}
+
+	if result.Part1Overflow && result.Part1Big == nil {
+		warnf("part 1 overflows 64-bit integers, use -bigint for the right answer.")
+	}
+	if result.Part2Overflow && result.Part2Big == nil {
+		warnf("part 2 overflows 64-bit integers, use -bigint for the right answer.")
+	}
+	for _, group := range result.Groups {
+		if group.Overflow && group.BigValue == nil {
+			warnf("group %s overflows 64-bit integers, use -bigint for the right answer.", strings.TrimSpace(group.Prefix))
+		}
+	}
+
+	for _, field := range result.Part2Unknown {
+		warnf("your ticket's value for %q is unknown, part 2 leaves it out.", field)
+	}
+	for _, group := range result.Groups {
+		for _, field := range group.Unknown {
+			warnf("your ticket's value for %q is unknown, group %s leaves it out.", field, strings.TrimSpace(group.Prefix))
+		}
+	}
+
+	if !result.Certain() {
+		warnf("the ordering was picked among several consistent ones, part 2 may be wrong.")
+	}
 }
 
 func main() {
-	// Let's open the file
-	file, err := os.Open("input.txt")
+	input := addInputFlags(flag.CommandLine)
+	validation := addValidationFlags(flag.CommandLine)
+	ordering := addOrderingFlags(flag.CommandLine)
+	session := flag.String("session", "", "adventofcode.com session cookie used by fetch, defaults to $"+SessionEnv)
+	sessionFile := flag.String("session-file", "", "file holding the adventofcode.com session cookie used by fetch, when neither -session nor $"+SessionEnv+" give it")
+	cacheDir := flag.String("cache-dir", "", "directory where fetch keeps the downloaded inputs, defaults to the user cache directory")
+	refresh := flag.Bool("refresh", false, "make fetch download the input again even when it is cached")
+	aggregate := flag.String("aggregate", string(ticket16.AggregateProduct), "how the values of part 2 and of the groups are combined: \"product\", \"sum\", \"min\", \"max\" or \"list\"")
+	bigInt := flag.Bool("bigint", false, "compute the answers with arbitrary precision, for notes whose sums and products overflow 64-bit integers")
+	var groups stringList
+	flag.Var(&groups, "group", "prefix of fields whose values of your ticket are combined like the departure fields, may be given several times")
+	orderings := flag.Int("orderings", 0, "also list up to this many orderings consistent with the tickets, to see all the answers of ambiguous notes")
+	metrics := flag.String("metrics", "", "also break part 1 down, comma-separated among \"discarded\" tickets, values accepted by the \"rules\", invalid values by \"positions\", or \"all\"")
+	stats := flag.Bool("stats", false, "also write statistics of the values of the valid nearby tickets at each position, before solving")
+	coverage := flag.Bool("coverage", false, "also write how many values of the nearby tickets each rule matches, the dead rules, and the gaps between the rules the invalid values fall in")
+	discarded := flag.Bool("discarded", false, "also list the nearby tickets discarded as invalid, with their invalid values, and their line and text when read from text notes")
+	repairs := flag.Bool("repairs", false, "also suggest the values that the invalid values were likely meant to be")
+	stream := flag.Bool("stream", false, "solve the text notes in a single pass without keeping the tickets in memory, for very large inputs")
+	chunkSize := flag.Int("chunk-size", ticket16.DefaultChunkSize, "number of nearby tickets -stream folds at once")
+	statePath := flag.String("state", "", "file keeping what -stream learned from the nearby tickets, so that the next run on the same input with more tickets appended only folds in the new ones")
+	asJSON := flag.Bool("json", false, "write the result as a single JSON object, with both parts, the ordering, the invalid tickets and the timings, instead of text")
+	outputPath := flag.String("output", "", "write the report to this file instead of the standard output, replacing it only once complete")
+	reportPath := flag.String("report", "", "also write a self-contained HTML page with the answers, your ticket decoded, the invalid tickets, the coverage of the rules and the candidates of each position to this file, to share them")
+	positions := flag.Bool("positions", false, "also write the field found at each position, like the order command, to check part 2 by hand")
+	mine := flag.Bool("mine", false, "also write your ticket decoded, as a table of its fields and their values")
+	timings := flag.Bool("time", false, "also write the wall time spent parsing the notes, validating the tickets and inferring the ordering, with -stream the validation includes the parsing")
+	throughput := flag.Bool("throughput", false, "also write the tickets parsed and the values validated per second, the rounds of the elimination and the peak memory, with -stream the validation includes the parsing")
+	verbose := flag.Bool("v", false, "also log the summaries of the parsing, of the validation and of the ordering on stderr")
+	debug := flag.Bool("vv", false, "also log each round of the elimination on stderr, besides what -v logs")
+	noColor := flag.Bool("no-color", false, "never paint the invalid values, the warnings and the departure fields, which are painted on terminals unless $"+NoColorEnv+" is set")
+	cpuProfile := flag.String("cpuprofile", "", "write a CPU profile to this file, when the command ends normally")
+	memProfile := flag.String("memprofile", "", "write a heap profile to this file, when the command ends normally")
+	traceFile := flag.String("trace", "", "write an execution trace to this file, when the command ends normally")
+	configPath := flag.String("config", DefaultConfigPath(), "YAML file holding the defaults of the flags, see the configuration section below, \"\" for none")
+	flag.Usage = usage
+	flag.Parse()
+
+	// Only the configuration given on the command line has to exist.
+	configGiven := false
+	flag.Visit(func(f *flag.Flag) {
+		configGiven = configGiven || f.Name == "config"
+	})
+	if *configPath != "" {
+		loaded, err := loadConfig(*configPath, configGiven)
+		if err != nil {
+			log.Fatalf("Unable to load the configuration. %s.", err)
+		}
+		config = loaded
+	}
+
+	// The configuration is applied once the flags after solve and fetch are parsed too.
+	run, focused := commands[flag.Arg(0)]
+	var command, inputPath string
+	if focused {
+		if err := checkCommandFlags(flag.Arg(0)); err != nil {
+			usageError(flag.Usage, err)
+		}
+	} else {
+		var err error
+		command, inputPath, err = parseArgs(flag.Args())
+		if err != nil {
+			usageError(flag.Usage, err)
+		}
+	}
+	configured, err := applyConfig(flag.CommandLine, config.Flags, "")
 	if err != nil {
-		log.Fatalf("Unable to open input file. %s.", err)
+		log.Fatalf("Unable to apply the configuration. %s.", err)
 	}
-	defer file.Close() // Close the file
 
-	readConfiguration := true // First reading will be the configuration.
-	readYourTicket := false   // We are not reading "your ticket" details until told to.
-	readNearbyTicket := false // We are not reading "nearby tickets" details until told to.
+	setVerbosity(*verbose, *debug)
+	setColor(*noColor)
 
-	configs := make([]Configuration, 0)
-	myTicket := Ticket{}
-	validNearbyTickets := make([]Ticket, 0)
-	invalidValues := make([]int, 0)
+	// The conflicts are checked on the values of both the command line and the configuration.
+	if !focused {
+		if err := checkJSONFlags(configured); err != nil {
+			usageError(flag.Usage, err)
+		}
+		if err := checkStreamFlags(command, inputFormat(ticket16.Format(*input.format), inputPath), configured); err != nil {
+			usageError(flag.Usage, err)
+		}
+	}
 
-	// Create a reader to read line by line
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := scanner.Text()
+	stopProfiling, err := startProfiling(ProfileOptions{CPU: *cpuProfile, Memory: *memProfile, Trace: *traceFile})
+	if err != nil {
+		log.Fatalf("%s.", err)
+	}
+	defer stopProfiling()
 
-		// Ignore empty line
-		if len(line) == 0 {
-			continue
+	// Stop solving cleanly when interrupted.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if focused {
+		run(ctx, flag.Args()[1:])
+		return
+	}
+
+	input.keepSources = *discarded || *reportPath != ""
+	parseOpts, err := input.parseOptions()
+	if err != nil {
+		usageError(flag.Usage, err)
+	}
+
+	orderingOpts, err := ordering.options()
+	if err != nil {
+		usageError(flag.Usage, err)
+	}
+
+	aggregation, err := ticket16.ParseAggregation(*aggregate)
+	if err != nil {
+		usageError(flag.Usage, err)
+	}
+
+	selectedMetrics, err := parseMetrics(*metrics)
+	if err != nil {
+		usageError(flag.Usage, err)
+	}
+
+	solverOpts := append(validation.options(), orderingOpts...)
+	solverOpts = append(solverOpts,
+		ticket16.WithParseOptions(parseOpts),
+		ticket16.WithGroups(groups...),
+		ticket16.WithAggregation(aggregation),
+		ticket16.WithBigInt(*bigInt),
+		ticket16.WithMetrics(len(selectedMetrics) > 0),
+		ticket16.WithInvalidDetails(*asJSON),
+		ticket16.WithChunkSize(*chunkSize),
+	)
+
+	// The report is only written to the output file once complete, so that a failed solve keeps the
+	// previous one: log.Fatalf exits without running the deferred functions.
+	output := io.Writer(os.Stdout)
+	if *outputPath != "" {
+		var report bytes.Buffer
+		output = &report
+		defer func() {
+			if err := writeAtomic(*outputPath, &report, 0o644); err != nil {
+				log.Fatalf("Unable to write the output. %s.", err)
+			}
+		}()
+	}
+
+	if *statePath != "" && !*stream {
+		usageError(flag.Usage, errors.New("-state requires -stream"))
+	}
+
+	if *stream {
+		result, myTicket := runStream(ctx, ticket16.NewSolver(solverOpts...), inputPath, input.remote(), *input.warnOverlaps, *statePath)
+		writeResult(output, result, *asJSON)
+		if *positions {
+			printPositions(output, result.Positions, false)
+		}
+		if *mine {
+			printTable(output, "your ticket", myTicket, result.Ordering)
 		}
+		if *timings {
+			printTimings(output, result.Timings)
+		}
+		if *throughput {
+			printThroughput(output, result, 0)
+		}
+		return
+	}
 
-		// Check if we are reading "your ticket" or "nearby tickets". Other than that, we are reading
-		// configuration.
-		if strings.HasPrefix(line, YourTicket) {
-			// Reading our own ticket later. Set the flag.
-			readConfiguration = false
-			readYourTicket = true
-			readNearbyTicket = false
-		} else if strings.HasPrefix(line, NearbyTickets) {
-			// Reading nearby tickets. Set the flag
-			readConfiguration = false
-			readYourTicket = false
-			readNearbyTicket = true
-		} else {
-			// Reading the data and process based on the flag.
-			if readConfiguration {
-				// Process the configuration
-				newConfig := parseConfiguration(line)
-				configs = append(configs, newConfig)
-			} else if readYourTicket {
-				// Process our own ticket. Our own ticket is assumed to be always valid.
-				myTicket = parseTicket(line)
-				validNearbyTickets = append(validNearbyTickets, myTicket)
-			} else if readNearbyTicket {
-				// Process the nearby ticket
-				nearbyTicket := parseTicket(line)
-
-				valid, invalids := isValidTicket(nearbyTicket, configs)
-				if !valid {
-					invalidValues = append(invalidValues, invalids...)
-				} else {
-					validNearbyTickets = append(validNearbyTickets, nearbyTicket)
-				}
+	start := time.Now()
+	var notes *ticket16.Notes
+	if command == "fetch" && !*input.example {
+		if *session == "" {
+			*session = os.Getenv(SessionEnv)
+		}
+		if *session == "" && *sessionFile != "" {
+			data, err := os.ReadFile(*sessionFile)
+			if err != nil {
+				log.Fatalf("Unable to read the session. %s.", err)
 			}
+			*session = strings.TrimSpace(string(data))
 		}
+
+		notes, err = fetchNotes(*session, *cacheDir, *refresh, parseOpts)
+		if err == nil {
+			input.report(notes)
+		}
+	} else {
+		notes, err = input.load(inputPath, parseOpts)
 	}
+	if err != nil {
+		log.Fatalf("Unable to load input. %s.", err)
+	}
+	parseTime := time.Since(start)
+
+	if *coverage {
+		printCoverage(output, ticket16.AnalyzeCoverage(notes.Configs, notes.NearbyTickets))
+	}
+
+	solver := ticket16.NewSolver(solverOpts...)
+	// The statistics come first, since they help understanding why the ordering can't be found.
+	if *stats {
+		validation, err := solver.ValidateContext(ctx, notes)
+		if err != nil {
+			log.Fatalf("Unable to validate the tickets. %s.", err)
+		}
+
+		printStats(output, ticket16.ValueStats(validation.Valid, ticket16.DefaultHistogramBins))
+	}
+
+	result, err := solver.SolveNotesContext(ctx, notes)
+	if err != nil {
+		log.Fatalf("Unable to solve. %s.", err)
+	}
+
+	// The notes were read here rather than by the solver, which couldn't time it.
+	result.Timings.Parse = parseTime
 
-	sum := 0
-	for _, value := range invalidValues {
-		sum += value
+	logSummary(result)
+	warnResult(result)
+	writeResult(output, result, *asJSON)
+	if result.Metrics != nil && !*asJSON {
+		printMetrics(output, result.Metrics, selectedMetrics)
 	}
-	fmt.Println(sum)
+	if *positions {
+		printPositions(output, result.Positions, false)
+	}
+	if *mine {
+		printTable(output, "your ticket", notes.MyTicket, result.Ordering)
+	}
+	if *timings {
+		printTimings(output, result.Timings)
+	}
+	if *throughput {
+		printThroughput(output, result, parseTime)
+	}
+
+	if *orderings > 0 {
+		// Asking for one more tells whether some were left out.
+		consistent, err := solver.OrderingsContext(ctx, notes, *orderings+1)
+		if err != nil {
+			log.Fatalf("Unable to list the orderings. %s.", err)
+		}
+
+		printOrderings(output, consistent, *orderings)
+	}
+
+	if *discarded {
+		validation, err := solver.ValidateContext(ctx, notes)
+		if err != nil {
+			log.Fatalf("Unable to validate the tickets. %s.", err)
+		}
+
+		printInvalid(output, "discarded", validation.Invalid, notes.Sources)
+	}
+
+	if *repairs {
+		validation, err := solver.ValidateContext(ctx, notes)
+		if err != nil {
+			log.Fatalf("Unable to validate the tickets. %s.", err)
+		}
+
+		// The tolerated tickets were kept, but their invalid values may need repairing just as well.
+		nearby := append(validation.Invalid, validation.Tolerated...)
+		sort.SliceStable(nearby, func(i, j int) bool { return nearby[i].Index < nearby[j].Index })
+		printRepairs(output, result.MyTicketInvalid, nearby, notes.Configs)
+	}
+
+	if *reportPath != "" {
+		validation, err := solver.ValidateContext(ctx, notes)
+		if err != nil {
+			log.Fatalf("Unable to validate the tickets. %s.", err)
+		}
 
-	// Part 2, determine the fields ordering.
-	mul := 1
-	orderedFields := getOrdering(validNearbyTickets, configs)
-	for idx, field := range orderedFields {
-		if strings.HasPrefix(field, "departure ") {
-			mul *= myTicket.Values[idx]
+		if err := writeReport(*reportPath, notes, result, validation); err != nil {
+			log.Fatalf("Unable to write the report. %s.", err)
 		}
 	}
-	fmt.Println(mul)
 }
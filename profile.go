@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+)
+
+// ProfileOptions holds the paths the profiles are written to. The profiles whose path is empty are not
+// taken.
+type ProfileOptions struct {
+	CPU    string // CPU receives the CPU profile, sampled from the start to the end of the command.
+	Memory string // Memory receives the heap profile, taken at the end of the command.
+	Trace  string // Trace receives the execution trace, for "go tool trace".
+}
+
+// startProfiling starts the CPU profile and the execution trace. The returned function stops them and
+// writes the heap profile; it must be called before exiting for the profiles to be complete.
+func startProfiling(opts ProfileOptions) (func(), error) {
+	var stops []func()
+	stop := func() {
+		for idx := len(stops) - 1; idx >= 0; idx-- {
+			stops[idx]()
+		}
+	}
+
+	if opts.CPU != "" {
+		file, err := os.Create(opts.CPU)
+		if err != nil {
+			return nil, fmt.Errorf("unable to create CPU profile: %w", err)
+		}
+
+		if err := pprof.StartCPUProfile(file); err != nil {
+			file.Close()
+			return nil, fmt.Errorf("unable to start CPU profile: %w", err)
+		}
+
+		stops = append(stops, func() {
+			pprof.StopCPUProfile()
+			closeProfile(file)
+		})
+	}
+
+	if opts.Trace != "" {
+		file, err := os.Create(opts.Trace)
+		if err != nil {
+			stop()
+			return nil, fmt.Errorf("unable to create trace: %w", err)
+		}
+
+		if err := trace.Start(file); err != nil {
+			file.Close()
+			stop()
+			return nil, fmt.Errorf("unable to start trace: %w", err)
+		}
+
+		stops = append(stops, func() {
+			trace.Stop()
+			closeProfile(file)
+		})
+	}
+
+	if opts.Memory != "" {
+		stops = append(stops, func() {
+			writeHeapProfile(opts.Memory)
+		})
+	}
+
+	return stop, nil
+}
+
+// writeHeapProfile writes the heap profile to the path, after a garbage collection so that it shows the
+// memory still in use.
+func writeHeapProfile(path string) {
+	file, err := os.Create(path)
+	if err != nil {
+		log.Printf("Unable to create memory profile. %s.", err)
+		return
+	}
+
+	runtime.GC()
+	if err := pprof.WriteHeapProfile(file); err != nil {
+		log.Printf("Unable to write memory profile. %s.", err)
+	}
+	closeProfile(file)
+}
+
+// closeProfile closes a profile file, reporting the errors since they mean the profile is truncated.
+func closeProfile(file *os.File) {
+	if err := file.Close(); err != nil {
+		log.Printf("Unable to write %s. %s.", file.Name(), err)
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Default limits applied when reading the notes from a URL.
+const (
+	DefaultURLTimeout = 30 * time.Second
+	DefaultURLMaxSize = 64 << 20 // 64 MiB
+)
+
+// RemoteOptions limits the download of notes hosted behind a URL.
+type RemoteOptions struct {
+	// Timeout bounds the whole download, including reading the body. Zero means DefaultURLTimeout.
+	Timeout time.Duration
+	// MaxSize is the maximum number of bytes accepted, both as downloaded and once decompressed. Zero means
+	// DefaultURLMaxSize.
+	MaxSize int64
+}
+
+// maxSize returns MaxSize, or DefaultURLMaxSize when it is zero.
+func (o RemoteOptions) maxSize() int64 {
+	if o.MaxSize == 0 {
+		return DefaultURLMaxSize
+	}
+
+	return o.MaxSize
+}
+
+// isURL checks whether the input path is an HTTP(S) URL instead of a file.
+func isURL(path string) bool {
+	return strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://")
+}
+
+// OpenURL starts downloading the document at the given HTTP(S) URL. The body is streamed, and reading it
+// fails once more than MaxSize bytes have been received. The limit only applies to the bytes received, see
+// LimitDocument for the document once decompressed. The caller is responsible for closing the reader.
+func OpenURL(url string, opts RemoteOptions) (io.ReadCloser, error) {
+	if opts.Timeout == 0 {
+		opts.Timeout = DefaultURLTimeout
+	}
+	opts.MaxSize = opts.maxSize()
+
+	client := &http.Client{Timeout: opts.Timeout}
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("unable to download %s: %s", url, resp.Status)
+	}
+
+	// Don't even start when the server tells upfront that the document is too large.
+	if resp.ContentLength > opts.MaxSize {
+		resp.Body.Close()
+		return nil, fmt.Errorf("unable to download %s: %d bytes is larger than the limit of %d bytes", url, resp.ContentLength, opts.MaxSize)
+	}
+
+	return &limitedBody{body: resp.Body, url: url, remaining: opts.MaxSize}, nil
+}
+
+// LimitDocument fails the reads of the document downloaded from the URL once more than MaxSize bytes have
+// been read, like OpenURL does for the bytes received. It keeps a compressed document from growing past the
+// limit once decompressed.
+func LimitDocument(document io.ReadCloser, url string, opts RemoteOptions) io.ReadCloser {
+	return &limitedBody{body: document, url: url, remaining: opts.maxSize()}
+}
+
+// limitedBody fails the reads once more than the allowed number of bytes have been read. Unlike
+// io.LimitReader, it reports an error instead of silently truncating the document.
+type limitedBody struct {
+	body      io.ReadCloser
+	url       string
+	remaining int64
+}
+
+// Read implements the io.Reader interface.
+func (l *limitedBody) Read(p []byte) (int, error) {
+	// Read one byte more than allowed to tell an exact fit from an overflow.
+	if int64(len(p)) > l.remaining+1 {
+		p = p[:l.remaining+1]
+	}
+
+	n, err := l.body.Read(p)
+	l.remaining -= int64(n)
+
+	if l.remaining < 0 {
+		return 0, fmt.Errorf("unable to download %s: document is larger than the size limit", l.url)
+	}
+
+	return n, err
+}
+
+// Close implements the io.Closer interface.
+func (l *limitedBody) Close() error {
+	return l.body.Close()
+}
@@ -0,0 +1,305 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/handracs2007/advent_of_code_2020_day16/ticket16"
+)
+
+// replHelp describes the commands of the repl.
+const replHelp = `answers             the answers of both parts
+field <position>    the field found at the position, and the fields the tickets allow there
+field <field>       the position of the field
+decode <values>     the fields of a ticket given as comma-separated values, and its invalid values
+why <field> <pos>   why the field is, or isn't, at the position
+invalid             the invalid values of the nearby tickets
+help                this help
+quit                leave the repl, like the end of the input
+`
+
+// replSession holds the solved notes the repl answers questions about.
+type replSession struct {
+	notes      *ticket16.Notes
+	opts       ticket16.ParseOptions
+	validation ticket16.Validation
+	result     ticket16.Result
+	myTicket   bool // myTicket tells whether your ticket was used to infer the ordering.
+}
+
+// runRepl runs the repl subcommand: it solves the input once, then answers the questions read from the
+// standard input, one per line, until its end.
+func runRepl(ctx context.Context, args []string) {
+	flags := flag.NewFlagSet("repl", flag.ExitOnError)
+	input := addInputFlags(flags)
+	validation := addValidationFlags(flags)
+	ordering := addOrderingFlags(flags)
+	flags.Usage = commandUsage(flags, "repl [repl flags] [input file]", "Solves the input, then answers the questions read from the standard input, one per line:\n\n"+replHelp)
+
+	parseCommandFlags(flags, args, true)
+	input.keepSources = true
+	notes, opts := loadInput(flags, input, interactiveInputPath(flags, input))
+	solver, result := solveNotes(ctx, flags, notes, opts, validation, ordering)
+
+	screened, err := solver.ValidateContext(ctx, notes)
+	if err != nil {
+		log.Fatalf("Unable to validate the tickets. %s.", err)
+	}
+
+	// The policy was checked by solveNotes already.
+	policy, _ := ticket16.ParseMyTicketPolicy(*ordering.myTicket)
+	session := &replSession{
+		notes:      notes,
+		opts:       opts,
+		validation: screened,
+		result:     result,
+		myTicket:   policy == ticket16.MyTicketInclude || policy == ticket16.MyTicketValidate && ticket16.ExplainTicket(notes.MyTicket, notes.Configs) == nil,
+	}
+
+	session.run(ctx, os.Stdin, os.Stdout, isTerminal(os.Stdin))
+}
+
+// interactiveInputPath returns the path of the input of the commands reading the standard input for
+// themselves, which the notes can't come from: the input defaults to the one of the configuration, or
+// DefaultInputPath, even when the standard input is piped.
+func interactiveInputPath(flags *flag.FlagSet, input *inputFlags) string {
+	path := defaultInput()
+	switch flags.NArg() {
+	case 0:
+	case 1:
+		path = flags.Arg(0)
+	default:
+		usageError(flags.Usage, fmt.Errorf("too many arguments: %s", strings.Join(flags.Args(), " ")))
+	}
+
+	if path == StdinPath && !*input.example {
+		usageError(flags.Usage, fmt.Errorf("%s reads the standard input, it can't read the notes from it too", flags.Name()))
+	}
+
+	return path
+}
+
+// readLines reads the lines of r aside, so that an interrupt doesn't wait for the next one. The channel is
+// closed at the end of r.
+func readLines(r io.Reader) <-chan string {
+	lines := make(chan string)
+	go func() {
+		defer close(lines)
+
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+	}()
+
+	return lines
+}
+
+// run answers the questions read from r until its end, a quit or the end of the context. The prompt is
+// only written when the questions are typed.
+func (s *replSession) run(ctx context.Context, r io.Reader, w io.Writer, prompt bool) {
+	lines := readLines(r)
+	for {
+		if prompt {
+			fmt.Fprint(w, "> ")
+		}
+
+		var line string
+		select {
+		case <-ctx.Done():
+			return
+		case next, ok := <-lines:
+			if !ok {
+				return
+			}
+			line = next
+		}
+
+		command, rest, _ := strings.Cut(strings.TrimSpace(line), " ")
+		rest = strings.TrimSpace(rest)
+
+		var err error
+		switch command {
+		case "":
+			continue
+		case "quit", "exit":
+			return
+		case "help":
+			fmt.Fprint(w, replHelp)
+		case "answers":
+			printResult(w, s.result)
+		case "field":
+			err = s.field(w, rest)
+		case "decode":
+			err = s.decode(w, rest)
+		case "why":
+			err = s.why(w, rest)
+		case "invalid":
+			nearby := append(append([]ticket16.InvalidTicket{}, s.validation.Invalid...), s.validation.Tolerated...)
+			sort.SliceStable(nearby, func(i, j int) bool { return nearby[i].Index < nearby[j].Index })
+			printInvalid(w, "invalid", nearby, s.notes.Sources)
+		default:
+			err = fmt.Errorf("unknown command %q, see \"help\"", command)
+		}
+
+		if err != nil {
+			fmt.Fprintf(w, "error: %s\n", err)
+		}
+	}
+}
+
+// field writes the field found at the position given as argument, or the position of the field given
+// as argument.
+func (s *replSession) field(w io.Writer, arg string) error {
+	if fieldPos, err := strconv.Atoi(arg); err == nil {
+		if fieldPos < 0 || fieldPos >= len(s.result.Positions) {
+			return fmt.Errorf("position %d is not between 0 and %d", fieldPos, len(s.result.Positions)-1)
+		}
+
+		printPositions(w, s.result.Positions[fieldPos:fieldPos+1], true)
+		return nil
+	}
+
+	for fieldPos, field := range s.result.Ordering {
+		if field == arg {
+			fmt.Fprintf(w, "%s: position %d\n", paintField(w, field), fieldPos)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("no field %q in the ordering", arg)
+}
+
+// decode writes the fields of the ticket given as argument, and its invalid values.
+func (s *replSession) decode(w io.Writer, arg string) error {
+	// The malformed ticket is worth an error, whatever the notes allowed.
+	opts := s.opts
+	opts.SkipMalformed = false
+
+	tickets, _, err := ticket16.ParseTickets(strings.NewReader(arg), opts)
+	if err != nil {
+		return err
+	}
+	if len(tickets) != 1 {
+		return errors.New("decode takes a single ticket")
+	}
+
+	ticket := tickets[0]
+	if len(ticket.Values) != len(s.result.Ordering) {
+		return fmt.Errorf("ticket has %d values instead of %d", len(ticket.Values), len(s.result.Ordering))
+	}
+
+	printDecoded(w, "ticket", ticket, s.result.Ordering)
+	for _, invalid := range ticket16.ExplainTicket(ticket, s.notes.Configs) {
+		fmt.Fprintf(w, "invalid: %s\n", paint(w, colorInvalid, invalid.String()))
+	}
+
+	return nil
+}
+
+// why explains why the field is at the position, or which ticket rules it out, or which field took the
+// position instead. The argument is the field followed by the position.
+func (s *replSession) why(w io.Writer, arg string) error {
+	words := strings.Fields(arg)
+	if len(words) < 2 {
+		return errors.New("why takes a field and a position")
+	}
+
+	fieldPos, err := strconv.Atoi(words[len(words)-1])
+	if err != nil {
+		return fmt.Errorf("invalid position %q", words[len(words)-1])
+	}
+	if fieldPos < 0 || fieldPos >= len(s.result.Positions) {
+		return fmt.Errorf("position %d is not between 0 and %d", fieldPos, len(s.result.Positions)-1)
+	}
+
+	field := strings.Join(words[:len(words)-1], " ")
+	config, ok := s.config(field)
+	if !ok {
+		return fmt.Errorf("no rule for field %q", field)
+	}
+
+	position := s.result.Positions[fieldPos]
+	if position.Field == field {
+		how := "every ordering consistent with the tickets puts it there"
+		if !position.Forced {
+			how = "it was picked among several orderings consistent with the tickets"
+		}
+
+		fmt.Fprintf(w, "%s is at position %d: the tickets allow %s there, and %s\n", field, fieldPos, strings.Join(position.Candidates, ", "), how)
+		return nil
+	}
+
+	if ticket, value, ok := s.ruledOut(config, fieldPos); ok {
+		fmt.Fprintf(w, "%s can't be at position %d: %s holds %s there, outside %s\n", field, fieldPos, ticket, paint(w, colorInvalid, strconv.FormatInt(value, 10)), config)
+		return nil
+	}
+
+	at := "no position"
+	for other, name := range s.result.Ordering {
+		if name == field {
+			at = fmt.Sprintf("position %d", other)
+		}
+	}
+
+	fmt.Fprintf(w, "%s could be at position %d, which all the tickets allow, but the position went to %s, leaving %s to %s\n", field, fieldPos, position.Field, field, at)
+	return nil
+}
+
+// config returns the rule of the field.
+func (s *replSession) config(field string) (ticket16.Configuration, bool) {
+	for _, config := range s.notes.Configs {
+		if config.Field == field {
+			return config, true
+		}
+	}
+
+	return ticket16.Configuration{}, false
+}
+
+// ruledOut looks for the first ticket used to infer the ordering whose value at the position the rule
+// doesn't allow. The invalid values of the tolerated tickets don't count, like for the inference. It
+// returns the name of the ticket and its value.
+func (s *replSession) ruledOut(config ticket16.Configuration, fieldPos int) (string, int64, bool) {
+	skipped := map[int]bool{}
+	for _, ticket := range s.validation.Invalid {
+		skipped[ticket.Index] = true
+	}
+	for _, idx := range s.validation.Ragged {
+		skipped[idx] = true
+	}
+
+	invalidAt := map[int]bool{}
+	for _, ticket := range s.validation.Tolerated {
+		for _, invalid := range ticket.Values {
+			if invalid.Position == fieldPos {
+				invalidAt[ticket.Index] = true
+			}
+		}
+	}
+
+	for idx, ticket := range s.notes.NearbyTickets {
+		if skipped[idx] || invalidAt[idx] || fieldPos >= len(ticket.Values) || !ticket.Known(fieldPos) {
+			continue
+		}
+
+		if value := ticket.Values[fieldPos]; !config.Contains(value) {
+			return fmt.Sprintf("nearby ticket %d", idx), value, true
+		}
+	}
+
+	if mine := s.notes.MyTicket; s.myTicket && fieldPos < len(mine.Values) && mine.Known(fieldPos) && !config.Contains(mine.Values[fieldPos]) {
+		return "your ticket", mine.Values[fieldPos], true
+	}
+
+	return "", 0, false
+}
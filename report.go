@@ -0,0 +1,246 @@
+package main
+
+import (
+	"bytes"
+	"html/template"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/handracs2007/advent_of_code_2020_day16/ticket16"
+)
+
+// reportTemplate is the HTML page of -report. It is self-contained, with its style inlined and no script,
+// so that it can be mailed or attached as is.
+var reportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Ticket translation</title>
+<style>
+body { font-family: sans-serif; margin: 2em auto; max-width: 70em; color: #222; }
+h1, h2 { font-weight: normal; }
+table { border-collapse: collapse; margin: 1em 0; }
+th, td { padding: 0.2em 0.6em; text-align: left; border-bottom: 1px solid #ddd; }
+td.number { text-align: right; font-family: monospace; }
+.answer { font-size: 1.5em; font-family: monospace; }
+.departure { color: #07a; font-weight: bold; }
+.invalid { color: #c00; }
+.warning { color: #a60; }
+.bar { background: #7ab; height: 0.9em; min-width: 1px; }
+.dead { color: #c00; }
+table.matrix td { text-align: center; border: 1px solid #eee; padding: 0.2em; }
+table.matrix td.found { background: #07a; color: #fff; }
+table.matrix td.candidate { background: #cde; }
+table.matrix th.column { writing-mode: vertical-rl; transform: rotate(180deg); font-weight: normal; }
+</style>
+</head>
+<body>
+<h1>Ticket translation</h1>
+
+<h2>Answers</h2>
+<table>
+<tr><th>Part 1, the ticket scanning error rate</th><td class="answer">{{.Part1}}</td></tr>
+<tr><th>Part 2, the departure fields of your ticket</th><td class="answer">{{.Part2}}</td></tr>
+</table>
+<p>{{.ValidTickets}} valid nearby ticket(s), {{.ToleratedTickets}} tolerated, {{.InvalidTickets}} invalid.</p>
+{{range .Warnings}}<p class="warning">{{.}}</p>
+{{end}}
+<h2>Your ticket</h2>
+<table>
+<tr><th>Position</th><th>Field</th><th>Value</th></tr>
+{{range .Ticket}}<tr><td class="number">{{.Position}}</td><td{{if .Departure}} class="departure"{{end}}>{{.Field}}</td><td class="number">{{.Value}}</td></tr>
+{{end}}</table>
+
+<h2>Invalid tickets</h2>
+{{if .Invalid}}<table>
+<tr><th>Ticket</th><th>Line</th><th>Values</th><th>Invalid values</th></tr>
+{{range .Invalid}}<tr><td class="number">{{.Index}}{{if .Tolerated}} (tolerated){{end}}</td><td class="number">{{if .Line}}{{.Line}}{{end}}</td><td><code>{{.Text}}</code></td><td class="invalid">{{range .Values}}{{.}}<br>{{end}}</td></tr>
+{{end}}</table>
+{{else}}<p>Every nearby ticket is valid.</p>
+{{end}}
+<h2>Rule coverage</h2>
+<p>How many values of the nearby tickets each rule matches.</p>
+<table>
+{{range .Coverage}}<tr><td{{if .Dead}} class="dead"{{end}}>{{.Field}}</td><td class="number">{{.Accepted}}</td><td style="width: 30em"><div class="bar" style="width: {{.Percent}}%"></div></td></tr>
+{{end}}</table>
+{{if .Gaps}}<p>The invalid values fall between the rules in these gaps.</p>
+<table>
+{{range .Gaps}}<tr><td class="invalid">{{.}}</td></tr>
+{{end}}</table>
+{{end}}
+<h2>Candidates</h2>
+<p>The fields the valid tickets allow at each position, before the elimination, and the field found there.
+Positions marked * were picked among several orderings as consistent with the tickets.</p>
+<table class="matrix">
+<tr><th>Position</th>{{range .Fields}}<th class="column">{{.}}</th>{{end}}</tr>
+{{range .Matrix}}<tr><th>{{.Position}}{{if not .Forced}} *{{end}}</th>{{range .Cells}}<td{{if .Found}} class="found"{{else if .Candidate}} class="candidate"{{end}}>{{if .Found}}●{{else if .Candidate}}·{{end}}</td>{{end}}</tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+// htmlReport holds what the page of -report shows.
+type htmlReport struct {
+	Part1, Part2                                   string
+	ValidTickets, ToleratedTickets, InvalidTickets int
+	Warnings                                       []string
+	Ticket                                         []reportField
+	Invalid                                        []reportTicket
+	Coverage                                       []reportRule
+	Gaps                                           []ticket16.GapCoverage
+	Fields                                         []string
+	Matrix                                         []reportPosition
+}
+
+// reportField is a field of your ticket, in the table of the report.
+type reportField struct {
+	Position  int
+	Field     string
+	Value     string
+	Departure bool
+}
+
+// reportTicket is an invalid or tolerated nearby ticket, in the list of the report. The line is only known
+// for the text notes, whose text is kept as is.
+type reportTicket struct {
+	Index     int
+	Tolerated bool
+	Line      int
+	Text      string
+	Values    []ticket16.InvalidValue
+}
+
+// reportRule is a bar of the coverage chart of the report, its length in percent of the longest one.
+type reportRule struct {
+	Field    string
+	Accepted int
+	Percent  int
+	Dead     bool
+}
+
+// reportPosition is a row of the candidate matrix of the report, with a cell by field in the order of the
+// rules.
+type reportPosition struct {
+	Position int
+	Forced   bool
+	Cells    []reportCell
+}
+
+// reportCell tells whether the field is a candidate of the position, and whether it was found there.
+type reportCell struct {
+	Candidate bool
+	Found     bool
+}
+
+// writeReport writes the HTML page of -report to the path, replacing it at once, see writeAtomic.
+func writeReport(path string, notes *ticket16.Notes, result ticket16.Result, validation ticket16.Validation) error {
+	report := htmlReport{
+		Part1:            result.Part1Answer(),
+		Part2:            result.Part2Answer(),
+		ValidTickets:     result.ValidTickets,
+		ToleratedTickets: result.ToleratedTickets,
+		InvalidTickets:   result.InvalidTickets,
+	}
+
+	if len(result.Part2Unknown) > 0 {
+		report.Warnings = append(report.Warnings, "Part 2 leaves out the unknown values of "+strings.Join(result.Part2Unknown, ", ")+".")
+	}
+	if !result.Certain() {
+		report.Warnings = append(report.Warnings, "Several orderings are consistent with the tickets, part 2 may differ with another one.")
+	}
+	for _, invalid := range result.MyTicketInvalid {
+		report.Warnings = append(report.Warnings, "Your ticket holds an invalid "+invalid.String()+".")
+	}
+
+	for fieldPos, field := range result.Ordering {
+		if fieldPos >= len(notes.MyTicket.Values) {
+			break
+		}
+
+		value := "?"
+		if notes.MyTicket.Known(fieldPos) {
+			value = strconv.FormatInt(notes.MyTicket.Values[fieldPos], 10)
+		}
+
+		report.Ticket = append(report.Ticket, reportField{
+			Position:  fieldPos,
+			Field:     field,
+			Value:     value,
+			Departure: strings.HasPrefix(field, ticket16.DeparturePrefix),
+		})
+	}
+
+	for _, ticket := range validation.Invalid {
+		report.Invalid = append(report.Invalid, newReportTicket(ticket, false, notes.Sources))
+	}
+	for _, ticket := range validation.Tolerated {
+		report.Invalid = append(report.Invalid, newReportTicket(ticket, true, notes.Sources))
+	}
+	sort.SliceStable(report.Invalid, func(i, j int) bool { return report.Invalid[i].Index < report.Invalid[j].Index })
+
+	coverage := ticket16.AnalyzeCoverage(notes.Configs, notes.NearbyTickets)
+	longest := 0
+	for _, rule := range coverage.Rules {
+		if rule.Accepted > longest {
+			longest = rule.Accepted
+		}
+	}
+	for _, rule := range coverage.Rules {
+		bar := reportRule{Field: rule.Field, Accepted: rule.Accepted, Dead: rule.Accepted == 0}
+		if longest > 0 {
+			bar.Percent = rule.Accepted * 100 / longest
+		}
+		report.Coverage = append(report.Coverage, bar)
+	}
+	for _, gap := range coverage.Gaps {
+		if gap.Invalid > 0 {
+			report.Gaps = append(report.Gaps, gap)
+		}
+	}
+
+	for _, config := range notes.Configs {
+		report.Fields = append(report.Fields, config.Field)
+	}
+	for _, position := range result.Positions {
+		candidates := map[string]bool{}
+		for _, field := range position.Candidates {
+			candidates[field] = true
+		}
+
+		row := reportPosition{Position: position.Position, Forced: position.Forced}
+		for _, field := range report.Fields {
+			row.Cells = append(row.Cells, reportCell{Candidate: candidates[field], Found: field == position.Field})
+		}
+		report.Matrix = append(report.Matrix, row)
+	}
+
+	var page bytes.Buffer
+	if err := reportTemplate.Execute(&page, report); err != nil {
+		return err
+	}
+
+	return writeAtomic(path, &page, 0o644)
+}
+
+// newReportTicket returns the ticket of the list of the report, with its line and text when known, its
+// comma-separated values otherwise.
+func newReportTicket(ticket ticket16.InvalidTicket, tolerated bool, sources []ticket16.TicketSource) reportTicket {
+	row := reportTicket{Index: ticket.Index, Tolerated: tolerated, Values: ticket.Values}
+	if ticket.Index < len(sources) {
+		row.Line = sources[ticket.Index].Line
+		row.Text = sources[ticket.Index].Text
+		return row
+	}
+
+	values := make([]string, len(ticket.Ticket.Values))
+	for fieldPos, value := range ticket.Ticket.Values {
+		values[fieldPos] = "?"
+		if ticket.Ticket.Known(fieldPos) {
+			values[fieldPos] = strconv.FormatInt(value, 10)
+		}
+	}
+	row.Text = strings.Join(values, ",")
+
+	return row
+}
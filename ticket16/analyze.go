@@ -0,0 +1,160 @@
+package ticket16
+
+import (
+	"fmt"
+	"sort"
+)
+
+// RuleIssueKind tells how two rules relate.
+type RuleIssueKind string
+
+const (
+	// RuleDuplicate tells that both rules allow the same values: their fields can't be told apart by any ticket.
+	RuleDuplicate RuleIssueKind = "duplicate"
+	// RuleContained tells that the rule of Field only allows values that the rule of Other allows too, so
+	// that a position matching Field always matches Other.
+	RuleContained RuleIssueKind = "contained"
+	// RuleOverlap tells that both rules allow some of the same values, and each allows values the other
+	// doesn't. It is common in the notes of the puzzle, and only a problem when the tickets hold nothing but
+	// shared values.
+	RuleOverlap RuleIssueKind = "overlap"
+)
+
+// RuleIssue describes two rules allowing some of the same values, which makes their fields harder to tell
+// apart.
+type RuleIssue struct {
+	Kind   RuleIssueKind
+	Field  string       // Field is the field of the first rule, the contained one for RuleContained.
+	Other  string       // Other is the field of the second rule.
+	Shared []ValidRange // Shared lists the values both rules allow, as sorted and merged ranges.
+}
+
+// String implements the fmt.Stringer interface.
+func (i RuleIssue) String() string {
+	switch i.Kind {
+	case RuleDuplicate:
+		return fmt.Sprintf("rules %q and %q allow the same values", i.Field, i.Other)
+	case RuleContained:
+		return fmt.Sprintf("rule %q only allows values that rule %q allows too", i.Field, i.Other)
+	default:
+		return fmt.Sprintf("rules %q and %q both allow %s", i.Field, i.Other, formatRanges(i.Shared))
+	}
+}
+
+// AnalyzeRules looks for the pairs of rules allowing some of the same values, in the order of the rules.
+// The exclusions of the rules are taken into account.
+func AnalyzeRules(configs []Configuration) []RuleIssue {
+	values := make([]RangeSet, len(configs))
+	for idx, config := range configs {
+		values[idx] = config.RangeSet().subtract(NewRangeSet(config.Exclusions...))
+	}
+
+	var issues []RuleIssue
+	for i := range configs {
+		for j := i + 1; j < len(configs); j++ {
+			shared := values[i].Intersect(values[j])
+			if shared.Len() == 0 {
+				continue
+			}
+
+			issue := RuleIssue{Kind: RuleOverlap, Field: configs[i].Field, Other: configs[j].Field, Shared: shared.Ranges()}
+			switch {
+			case values[i].Equal(values[j]):
+				issue.Kind = RuleDuplicate
+			case shared.Equal(values[i]):
+				issue.Kind = RuleContained
+			case shared.Equal(values[j]):
+				issue.Kind, issue.Field, issue.Other = RuleContained, configs[j].Field, configs[i].Field
+			}
+
+			issues = append(issues, issue)
+		}
+	}
+
+	return issues
+}
+
+// RedundantRules returns the issues that may keep the ordering from being found, the duplicate and the
+// contained rules, leaving the mere overlaps out.
+func RedundantRules(issues []RuleIssue) []RuleIssue {
+	var redundant []RuleIssue
+	for _, issue := range issues {
+		if issue.Kind != RuleOverlap {
+			redundant = append(redundant, issue)
+		}
+	}
+
+	return redundant
+}
+
+// Coverage tells how much of the rules the values of the tickets cover, see AnalyzeCoverage.
+type Coverage struct {
+	// Rules tells how many values of the tickets each rule matches, in the order of the rules.
+	Rules []RuleMetric
+
+	// Dead lists the fields whose rule matches none of the values, in the order of the rules.
+	Dead []string
+
+	// Gaps lists the values that no rule allows, as merged ranges, with the invalid values falling in each.
+	// The gaps holding the most invalid values come first, the others in the order of their values.
+	Gaps []GapCoverage
+}
+
+// GapCoverage tells how many invalid values fall between the ranges of the rules.
+type GapCoverage struct {
+	Range   ValidRange // Range holds the values that no rule allows.
+	Invalid int        // Invalid counts the values of the tickets falling in the Range.
+	Sum     int64      // Sum is the sum of these values.
+}
+
+// String implements the fmt.Stringer interface, e.g. "gap 4-4: 1 invalid value(s), summing to 4".
+func (g GapCoverage) String() string {
+	return fmt.Sprintf("gap %s: %d invalid value(s), summing to %d", formatRange(g.Range), g.Invalid, g.Sum)
+}
+
+// AnalyzeCoverage tells which rules the values of the tickets match, and which gaps between the ranges of
+// the rules their invalid values fall in. The exclusions of the rules are taken into account.
+func AnalyzeCoverage(configs []Configuration, tickets []Ticket) Coverage {
+	coverage := Coverage{Rules: make([]RuleMetric, len(configs))}
+
+	rules := compileRules(configs, nil)
+	allowed := make([]ValidRange, 0, len(configs))
+	for idx, config := range configs {
+		coverage.Rules[idx] = RuleMetric{Field: config.Field, Accepted: acceptedValues(rules[idx], tickets)}
+		if coverage.Rules[idx].Accepted == 0 {
+			coverage.Dead = append(coverage.Dead, config.Field)
+		}
+
+		allowed = append(allowed, config.RangeSet().subtract(NewRangeSet(config.Exclusions...)).Ranges()...)
+	}
+
+	everything := NewRangeSet(ValidRange{NoMin: true, NoMax: true})
+	for _, gap := range everything.subtract(NewRangeSet(allowed...)).Ranges() {
+		coverage.Gaps = append(coverage.Gaps, GapCoverage{Range: gap})
+	}
+
+	for _, ticket := range tickets {
+		for fieldPos, value := range ticket.Values {
+			if !ticket.Known(fieldPos) {
+				continue
+			}
+
+			// The gaps are sorted, the first one not ending before the value is the only one that may hold it.
+			idx := sort.Search(len(coverage.Gaps), func(i int) bool {
+				gap := coverage.Gaps[i].Range
+				return gap.NoMax || gap.Max >= value
+			})
+
+			if idx < len(coverage.Gaps) && coverage.Gaps[idx].Range.Contains(value) {
+				coverage.Gaps[idx].Invalid++
+				coverage.Gaps[idx].Sum += value
+			}
+		}
+	}
+
+	sort.SliceStable(coverage.Gaps, func(i, j int) bool {
+		return coverage.Gaps[i].Invalid > coverage.Gaps[j].Invalid
+	})
+
+	return coverage
+}
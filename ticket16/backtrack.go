@@ -0,0 +1,125 @@
+package ticket16
+
+import "context"
+
+// backtrackOrdering gets the ordering of the fields in the ticket with a depth-first search: it takes the
+// first position left, tries its candidates in the order of the rules, propagates each try with the
+// assignments it forces like getOrdering does, and backs up as soon as a position left has no candidate
+// anymore. The first ordering found thus follows the tie-break rule of Algorithm. It is exhaustive, which
+// makes it a correctness oracle for the other algorithms, but may take exponential time on pathological
+// rules.
+//
+// It tells for each position whether its field was forced, using the matching. It gives up with the error
+// of the context when the context is done, and with an UnsolvableError when there is no consistent
+// ordering. The search being a single round, the positions are all added to the progress at its end.
+func backtrackOrdering(ctx context.Context, candidates [][]bool, fields []string, progress *progressCounter) ([]string, []bool, error) {
+	orderings, err := allOrderings(ctx, candidates, fields, 1)
+	if err != nil {
+		return nil, nil, err
+	}
+	progress.add(len(candidates))
+
+	return orderings[0], newElimination(candidates, len(fields)).forced(), nil
+}
+
+// allOrderings enumerates the orderings of the fields consistent with the candidates, up to limit of them, or
+// all of them when limit is 0. They come sorted by the tie-break rule of Algorithm, position by position.
+// It gives up with the error of the context when the context is done, and with an UnsolvableError when
+// there is no consistent ordering.
+func allOrderings(ctx context.Context, candidates [][]bool, fields []string, limit int) ([][]string, error) {
+	state := newElimination(candidates, len(fields))
+	if err := state.unsolvable(fields); err != nil {
+		return nil, err
+	}
+
+	// More fields than positions leave some fields without a position, whatever the ordering.
+	if len(fields) > len(candidates) {
+		return nil, noOrdering(candidates, fields)
+	}
+
+	s := &backtracking{ctx: ctx, limit: limit}
+	s.search(state)
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	if len(s.solutions) == 0 {
+		return nil, noOrdering(candidates, fields)
+	}
+
+	orderings := make([][]string, len(s.solutions))
+	for solution, fieldOf := range s.solutions {
+		orderings[solution] = make([]string, len(fieldOf))
+		for fieldPos, idx := range fieldOf {
+			orderings[solution][fieldPos] = fields[idx]
+		}
+	}
+
+	return orderings, nil
+}
+
+// noOrdering returns the UnsolvableError telling why there is no consistent ordering: either the positions
+// that can't all be given a different field, or the fields left once the positions are all given one.
+func noOrdering(candidates [][]bool, fields []string) error {
+	state := newElimination(candidates, len(fields))
+	if positions := state.match(); positions != nil {
+		return state.unmatchable(fields, positions)
+	}
+
+	return state.unsolvable(fields)
+}
+
+// backtracking keeps the state of the search run by allOrderings.
+type backtracking struct {
+	ctx   context.Context
+	nodes int   // nodes counts the assignments tried, to check the context from time to time.
+	err   error // err is the error of the context once the search gave up.
+
+	limit     int     // limit is the number of solutions after which the search stops, 0 for no limit.
+	solutions [][]int // solutions holds the fields assigned to the positions by each complete assignment.
+}
+
+// search completes the assignments of the state, backing up on failure, and records each complete
+// assignment. It tells whether the search is over, having found enough solutions.
+func (s *backtracking) search(state *elimination) bool {
+	if s.nodes%contextCheckInterval == 0 {
+		if err := s.ctx.Err(); err != nil {
+			s.err = err
+			return true
+		}
+	}
+	s.nodes++
+
+	// Assigning what the state forces leaves nothing to search on puzzle-like rules.
+	for !state.done() {
+		if state.deadEnd() {
+			return false
+		}
+
+		if !state.step() {
+			break
+		}
+	}
+
+	if state.done() {
+		s.solutions = append(s.solutions, append([]int(nil), state.fieldOf...))
+		return s.limit > 0 && len(s.solutions) >= s.limit
+	}
+
+	fieldPos := 0
+	for state.fieldOf[fieldPos] >= 0 {
+		fieldPos++
+	}
+
+	row := state.candidates[fieldPos]
+	for idx := row.next(0); idx >= 0; idx = row.next(idx + 1) {
+		trial := state.clone()
+		trial.assign(fieldPos, idx)
+
+		if s.search(trial) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,83 @@
+package ticket16
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+// The benchmarks of the steps of the solve, run by the benchmarks of the package on generated notes, and
+// by testing.Benchmark on any notes, e.g. to compare optimizations on a real input. Each one fails the
+// benchmark on the first error, so the notes should be checked to solve beforehand.
+
+// ParseBenchmark benchmarks decoding the notes in the format, reporting the tickets parsed per second.
+func ParseBenchmark(data []byte, format Format, opts ParseOptions) func(b *testing.B) {
+	return func(b *testing.B) {
+		b.SetBytes(int64(len(data)))
+		b.ReportAllocs()
+
+		tickets := 0
+		for i := 0; i < b.N; i++ {
+			notes, err := DecodeNotes(bytes.NewReader(data), format, opts)
+			if err != nil {
+				b.Fatal(err)
+			}
+			tickets = len(notes.NearbyTickets)
+		}
+
+		reportRate(b, tickets, "tickets/s")
+	}
+}
+
+// ValidateBenchmark benchmarks validating the nearby tickets of the notes, reporting the tickets validated
+// per second. The bytes are those of the values validated.
+func ValidateBenchmark(solver *Solver, notes *Notes) func(b *testing.B) {
+	return func(b *testing.B) {
+		b.SetBytes(int64(len(notes.NearbyTickets)*len(notes.MyTicket.Values)) * 8)
+		b.ReportAllocs()
+
+		for i := 0; i < b.N; i++ {
+			if _, err := solver.ValidateContext(context.Background(), notes); err != nil {
+				b.Fatal(err)
+			}
+		}
+
+		reportRate(b, len(notes.NearbyTickets), "tickets/s")
+	}
+}
+
+// OrderBenchmark benchmarks inferring the ordering from the tickets, which must all be valid.
+func OrderBenchmark(solver *Solver, configs []Configuration, tickets []Ticket) func(b *testing.B) {
+	return func(b *testing.B) {
+		b.ReportAllocs()
+
+		for i := 0; i < b.N; i++ {
+			if _, err := solver.InferOrderingContext(context.Background(), configs, tickets); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+// SolveBenchmark benchmarks solving both parts of the notes, reporting the nearby tickets solved per
+// second.
+func SolveBenchmark(solver *Solver, notes *Notes) func(b *testing.B) {
+	return func(b *testing.B) {
+		b.ReportAllocs()
+
+		for i := 0; i < b.N; i++ {
+			if _, err := solver.SolveNotesContext(context.Background(), notes); err != nil {
+				b.Fatal(err)
+			}
+		}
+
+		reportRate(b, len(notes.NearbyTickets), "tickets/s")
+	}
+}
+
+// reportRate reports the number of items handled by each run per second.
+func reportRate(b *testing.B, items int, unit string) {
+	if elapsed := b.Elapsed(); elapsed > 0 {
+		b.ReportMetric(float64(items)*float64(b.N)/elapsed.Seconds(), unit)
+	}
+}
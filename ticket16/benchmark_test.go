@@ -0,0 +1,88 @@
+package ticket16
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+// generatedNotes generates the notes of the benchmarks, along with their text.
+func generatedNotes(b *testing.B, opts GenerateOptions) (*Notes, []byte) {
+	b.Helper()
+
+	generated, err := GenerateNotes(opts)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	var text bytes.Buffer
+	if err := WriteNotes(&text, generated.Notes); err != nil {
+		b.Fatal(err)
+	}
+
+	return generated.Notes, text.Bytes()
+}
+
+// benchmarkNotes are the options of the notes of most benchmarks: the size of the puzzle input, ten
+// times over.
+var benchmarkNotes = GenerateOptions{Fields: 20, Tickets: 2500, InvalidRate: 0.2, Seed: 1}
+
+func BenchmarkParse(b *testing.B) {
+	_, text := generatedNotes(b, benchmarkNotes)
+	ParseBenchmark(text, FormatText, ParseOptions{})(b)
+}
+
+func BenchmarkValidate(b *testing.B) {
+	notes, _ := generatedNotes(b, benchmarkNotes)
+	ValidateBenchmark(NewSolver(), notes)(b)
+}
+
+func BenchmarkOrder(b *testing.B) {
+	notes, _ := generatedNotes(b, benchmarkNotes)
+	solver := NewSolver()
+	OrderBenchmark(solver, notes.Configs, solver.Validate(notes).Valid)(b)
+}
+
+func BenchmarkSolve(b *testing.B) {
+	notes, _ := generatedNotes(b, benchmarkNotes)
+	SolveBenchmark(NewSolver(), notes)(b)
+}
+
+// BenchmarkOrderWide compares the bitset elimination with the matching on the candidates of wide notes,
+// which span several words of the bitsets. The candidates are computed once, so that only the algorithms
+// are timed.
+func BenchmarkOrderWide(b *testing.B) {
+	notes, _ := generatedNotes(b, GenerateOptions{Fields: 200, Tickets: 1000, Seed: 1})
+	rules := compileRules(notes.Configs, nil)
+	candidates, err := orderingCandidates(rules, NewSolver().Validate(notes).Valid, nil, 1)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	for _, algorithm := range []Algorithm{AlgorithmElimination, AlgorithmMatching} {
+		b.Run(string(algorithm), func(b *testing.B) {
+			solver := NewSolver(WithAlgorithm(algorithm))
+			b.ReportAllocs()
+
+			for i := 0; i < b.N; i++ {
+				if _, _, _, err := solver.inferOrdering(context.Background(), candidates, ruleNames(rules)); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkValidateMillion validates a million tickets, the size the block-wise validation is tuned for.
+func BenchmarkValidateMillion(b *testing.B) {
+	if testing.Short() {
+		b.Skip("generating a million tickets is slow")
+	}
+
+	generated, err := GenerateNotes(GenerateOptions{Fields: 20, Tickets: 1000000, InvalidRate: 0.2, Seed: 1})
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	ValidateBenchmark(NewSolver(), generated.Notes)(b)
+}
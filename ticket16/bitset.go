@@ -0,0 +1,96 @@
+package ticket16
+
+import "math/bits"
+
+// bitset is a set of small non-negative integers, e.g. the candidate fields of a position, packed 64 to a
+// word so that they are counted and searched a word at a time.
+type bitset []uint64
+
+// newBitset creates an empty set able to hold the integers below size.
+func newBitset(size int) bitset {
+	return make(bitset, (size+63)/64)
+}
+
+// has tells whether i is in the set.
+func (b bitset) has(i int) bool {
+	return b[i/64]&(1<<(uint(i)%64)) != 0
+}
+
+// set adds i to the set.
+func (b bitset) set(i int) {
+	b[i/64] |= 1 << (uint(i) % 64)
+}
+
+// clear removes i from the set.
+func (b bitset) clear(i int) {
+	b[i/64] &^= 1 << (uint(i) % 64)
+}
+
+// reset empties the set.
+func (b bitset) reset() {
+	for word := range b {
+		b[word] = 0
+	}
+}
+
+// count counts the integers in the set.
+func (b bitset) count() int {
+	count := 0
+	for _, word := range b {
+		count += bits.OnesCount64(word)
+	}
+
+	return count
+}
+
+// countAnd counts the integers in both sets, which have the same size.
+func (b bitset) countAnd(other bitset) int {
+	count := 0
+	for word := range b {
+		count += bits.OnesCount64(b[word] & other[word])
+	}
+
+	return count
+}
+
+// next returns the smallest integer of the set not below i, or -1 when there is none. It lets the set be
+// walked in order with: for i := b.next(0); i >= 0; i = b.next(i + 1).
+func (b bitset) next(i int) int {
+	word := i / 64
+	if word >= len(b) {
+		return -1
+	}
+
+	// Drop the integers below i from the first word.
+	if rest := b[word] >> (uint(i) % 64); rest != 0 {
+		return i + bits.TrailingZeros64(rest)
+	}
+
+	for word++; word < len(b); word++ {
+		if b[word] != 0 {
+			return word*64 + bits.TrailingZeros64(b[word])
+		}
+	}
+
+	return -1
+}
+
+// nextAnd is like next, for the integers in both sets, which have the same size.
+func (b bitset) nextAnd(other bitset, i int) int {
+	word := i / 64
+	if word >= len(b) {
+		return -1
+	}
+
+	if rest := (b[word] & other[word]) >> (uint(i) % 64); rest != 0 {
+		return i + bits.TrailingZeros64(rest)
+	}
+
+	for word++; word < len(b); word++ {
+		if both := b[word] & other[word]; both != 0 {
+			return word*64 + bits.TrailingZeros64(both)
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,88 @@
+package ticket16
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// BoundPolicy tells whether the bounds of the "<min>-<max>" ranges belong to the ranges.
+type BoundPolicy string
+
+const (
+	// BoundsInclusive includes both bounds, like the puzzle. It is the default policy.
+	BoundsInclusive BoundPolicy = "inclusive"
+	// BoundsExclusive excludes both bounds: "1-5" allows 2 to 4.
+	BoundsExclusive BoundPolicy = "exclusive"
+	// BoundsExclusiveMin excludes the minimum only: "1-5" allows 2 to 5.
+	BoundsExclusiveMin BoundPolicy = "exclusive-min"
+	// BoundsExclusiveMax excludes the maximum only, like half-open intervals: "1-5" allows 1 to 4.
+	BoundsExclusiveMax BoundPolicy = "exclusive-max"
+)
+
+// ParseBoundPolicy checks the name of a bound policy, as written on the command line.
+func ParseBoundPolicy(name string) (BoundPolicy, error) {
+	switch policy := BoundPolicy(name); policy {
+	case BoundsInclusive, BoundsExclusive, BoundsExclusiveMin, BoundsExclusiveMax:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unknown bound policy %q", name)
+	}
+}
+
+// exclusive tells which bounds of the "<min>-<max>" ranges are excluded.
+func (p BoundPolicy) exclusive() (min bool, max bool) {
+	return p == BoundsExclusive || p == BoundsExclusiveMin, p == BoundsExclusive || p == BoundsExclusiveMax
+}
+
+// isInterval tells whether the range is written in interval notation, e.g. "[1,5)".
+func isInterval(rng string) bool {
+	return strings.HasPrefix(rng, "[") || strings.HasPrefix(rng, "(")
+}
+
+// parseInterval parses a range written in interval notation found at the given 0-based offset of the line,
+// where a square bracket includes the bound and a parenthesis excludes it: "[1,5)" allows 1 to 4.
+func parseInterval(rng string, offset int, opts ParseOptions) (ValidRange, error) {
+	last := rng[len(rng)-1]
+	if last != ']' && last != ')' {
+		return ValidRange{}, newParseError(offset, "interval '%s' is not closed by ']' or ')'", rng)
+	}
+
+	commaIdx := strings.Index(rng, ",")
+	if commaIdx < 0 {
+		return ValidRange{}, newParseError(offset, "interval '%s' has no ',' between its bounds", rng)
+	}
+
+	min, err := parseBound(rng[1:commaIdx], offset+1, "minimum", opts)
+	if err != nil {
+		return ValidRange{}, err
+	}
+
+	max, err := parseBound(rng[commaIdx+1:len(rng)-1], offset+commaIdx+1, "maximum", opts)
+	if err != nil {
+		return ValidRange{}, err
+	}
+
+	return excludeBounds(rng, offset, min, max, rng[0] == '(', last == ')')
+}
+
+// excludeBounds returns the range from min to max, moving the excluded bounds to the closest integer inside
+// the range, so that the range is closed like the others. It returns a ParseError when no integer is left.
+func excludeBounds(rng string, offset int, min int64, max int64, minExcluded bool, maxExcluded bool) (ValidRange, error) {
+	if (minExcluded && min == math.MaxInt64) || (maxExcluded && max == math.MinInt64) {
+		return ValidRange{}, newParseError(offset, "range '%s' holds no integer", rng)
+	}
+
+	if minExcluded {
+		min++
+	}
+	if maxExcluded {
+		max--
+	}
+
+	if (minExcluded || maxExcluded) && min > max {
+		return ValidRange{}, newParseError(offset, "range '%s' holds no integer", rng)
+	}
+
+	return ValidRange{Min: min, Max: max}, nil
+}
@@ -0,0 +1,95 @@
+package ticket16
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// CandidateMatrix tells which fields may be held by which positions of the tickets, before any elimination.
+// It lets other tools run their own assignment algorithms, or draw the problem.
+type CandidateMatrix struct {
+	Fields    []string // Fields lists the fields, in the order of the rules. They are the rows of the matrix.
+	Positions int      // Positions is the number of positions of the tickets. They are the columns of the matrix.
+
+	// Cells tells, for each field and each position, whether the field matches the values of all the
+	// tickets at the position: Cells[field][position].
+	Cells [][]bool
+}
+
+// Candidate tells whether the field at the given row may be held by the given position.
+func (m CandidateMatrix) Candidate(field int, position int) bool {
+	return m.Cells[field][position]
+}
+
+// CandidateMatrix computes the candidate matrix of the rules, and of the custom rules of the solver, from
+// the given tickets. The tickets should be the valid ones, e.g. Validation.Valid and your ticket.
+func (s *Solver) CandidateMatrix(configs []Configuration, tickets []Ticket) (CandidateMatrix, error) {
+	if len(tickets) == 0 {
+		return CandidateMatrix{}, errors.New("no valid ticket to compute the candidates from")
+	}
+
+	positions := len(tickets[0].Values)
+	for idx, ticket := range tickets {
+		if len(ticket.Values) != positions {
+			return CandidateMatrix{}, fmt.Errorf("ticket %d has %d values instead of %d", idx, len(ticket.Values), positions)
+		}
+	}
+
+	rules := compileRules(configs, s.rules)
+	return newCandidateMatrix(rules, positions, candidateMatrix(positions, tickets, rules, s.workers())), nil
+}
+
+// NotesCandidateMatrix computes the candidate matrix the ordering of the notes is inferred from: the
+// tickets are picked like for SolveNotes, and the tolerated ones narrow the candidates. It still works out
+// when no ordering is consistent with the tickets, to tell why.
+func (s *Solver) NotesCandidateMatrix(notes *Notes) (CandidateMatrix, error) {
+	return s.NotesCandidateMatrixContext(context.Background(), notes)
+}
+
+// NotesCandidateMatrixContext is like NotesCandidateMatrix, but gives up with the error of the context
+// when the context is done.
+func (s *Solver) NotesCandidateMatrixContext(ctx context.Context, notes *Notes) (CandidateMatrix, error) {
+	// Only the validity of the tickets matters here.
+	validation, err := s.validate(ctx, notes, CollectFirst)
+	if err != nil {
+		return CandidateMatrix{}, err
+	}
+
+	tickets, err := s.orderingTickets(notes, validation)
+	if err != nil {
+		return CandidateMatrix{}, err
+	}
+	if s.dedup {
+		tickets, _ = dedupTickets(tickets)
+	}
+
+	rules := compileRules(notes.Configs, s.rules)
+	byPosition, err := orderingCandidates(rules, tickets, validation.Tolerated, s.workers())
+	if err != nil {
+		return CandidateMatrix{}, err
+	}
+
+	return newCandidateMatrix(rules, len(byPosition), byPosition), nil
+}
+
+// newCandidateMatrix turns the candidates of each position, as computed by candidateMatrix, into the
+// matrix of the fields.
+func newCandidateMatrix(rules []Rule, positions int, byPosition [][]bool) CandidateMatrix {
+	matrix := CandidateMatrix{
+		Fields:    make([]string, len(rules)),
+		Positions: positions,
+		Cells:     make([][]bool, len(rules)),
+	}
+
+	for idx, rule := range rules {
+		matrix.Fields[idx] = rule.Name()
+		matrix.Cells[idx] = make([]bool, positions)
+
+		for fieldPos := range byPosition {
+			matrix.Cells[idx][fieldPos] = byPosition[fieldPos][idx]
+		}
+	}
+
+	return matrix
+}
@@ -0,0 +1,127 @@
+package ticket16
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"math/big"
+)
+
+// Checkpoint is what a streamed solve learned from the nearby tickets, see Solver.ResumeStream. It lets the
+// solve of notes that only grew since, by new nearby tickets at their end, fold in the new tickets alone.
+type Checkpoint struct {
+	// Rules is a digest of the rules and of the options the tickets were folded with. A checkpoint is only
+	// used by the solves of the same rules. The custom rules are only told apart by their names.
+	Rules string
+
+	// Tickets counts the nearby tickets folded, and Digest is the state of the hash of their values, which
+	// the notes resuming from the checkpoint must start with.
+	Tickets int
+	Digest  []byte
+
+	// Candidates lists, by position, the indexes of the rules matching all the tickets folded.
+	Candidates [][]int
+
+	// ErrorRate sums the invalid values of the tickets folded, and ErrorRateBig too when the solve was asked
+	// to compute with arbitrary precision. ErrorRateOverflow tells that ErrorRate wrapped around. Valid,
+	// Invalid and Tolerated count the tickets.
+	ErrorRate         int64
+	ErrorRateOverflow bool
+	ErrorRateBig      *big.Int
+	Valid             int
+	Invalid           int
+	Tolerated         int
+}
+
+// rulesDigest returns the digest of the rules, as compiled by compileRules, and of the options of the solver
+// changing the way the tickets are folded.
+func (s *Solver) rulesDigest(rules []Rule) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "max invalid %d, bigint %t\n", s.maxInvalid, s.bigInt)
+
+	for _, rule := range rules {
+		config, ok := rule.(compiledConfig[int64])
+		if !ok {
+			fmt.Fprintf(h, "%q: custom\n", rule.Name())
+			continue
+		}
+
+		fmt.Fprintf(h, "%q: %s not %s\n", config.field, formatRanges(config.ranges.ranges), formatRanges(config.exclusions.ranges))
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// hashState returns the state of the hash, which tells the data written to it apart like its sum does,
+// but can be written to further.
+func hashState(h hash.Hash) []byte {
+	state, err := h.(encoding.BinaryMarshaler).MarshalBinary()
+	if err != nil {
+		panic(err) // The hashes of the standard library always marshal.
+	}
+
+	return state
+}
+
+// resume takes the state of the folder from the checkpoint, once the tickets it folded were hashed again.
+func (f *chunkFolder) resume(checkpoint *Checkpoint, h hash.Hash) error {
+	if !bytes.Equal(hashState(h), checkpoint.Digest) {
+		return ErrCheckpointMismatch
+	}
+
+	if len(checkpoint.Candidates) > 0 {
+		f.candidates = make([]bitset, len(checkpoint.Candidates))
+		for fieldPos, indexes := range checkpoint.Candidates {
+			f.candidates[fieldPos] = newBitset(len(f.rules))
+			for _, idx := range indexes {
+				if idx < 0 || idx >= len(f.rules) {
+					return fmt.Errorf("checkpoint: rule %d at position %d out of the %d rules", idx, fieldPos, len(f.rules))
+				}
+
+				f.candidates[fieldPos].set(idx)
+			}
+		}
+	}
+
+	f.errorRate, f.errorRateOverflow = checkpoint.ErrorRate, checkpoint.ErrorRateOverflow
+	if f.errorRateBig != nil && checkpoint.ErrorRateBig != nil {
+		f.errorRateBig.Set(checkpoint.ErrorRateBig)
+	}
+	f.valid, f.invalid, f.tolerated = checkpoint.Valid, checkpoint.Invalid, checkpoint.Tolerated
+
+	return nil
+}
+
+// checkpoint returns the checkpoint of the tickets folded so far, whose values were written to the hash.
+func (f *chunkFolder) checkpoint(rules string, tickets int, h hash.Hash) *Checkpoint {
+	checkpoint := &Checkpoint{
+		Rules:             rules,
+		Tickets:           tickets,
+		Digest:            hashState(h),
+		ErrorRate:         f.errorRate,
+		ErrorRateOverflow: f.errorRateOverflow,
+		Valid:             f.valid,
+		Invalid:           f.invalid,
+		Tolerated:         f.tolerated,
+	}
+
+	if f.errorRateBig != nil {
+		checkpoint.ErrorRateBig = new(big.Int).Set(f.errorRateBig)
+	}
+
+	if f.candidates != nil {
+		checkpoint.Candidates = make([][]int, len(f.candidates))
+		for fieldPos, row := range f.candidates {
+			indexes := make([]int, 0)
+			for idx := row.next(0); idx >= 0; idx = row.next(idx + 1) {
+				indexes = append(indexes, idx)
+			}
+			checkpoint.Candidates[fieldPos] = indexes
+		}
+	}
+
+	return checkpoint
+}
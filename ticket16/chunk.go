@@ -0,0 +1,176 @@
+package ticket16
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// DefaultChunkSize is the number of nearby tickets SolveStream keeps before folding them into the
+// candidates, see WithChunkSize.
+const DefaultChunkSize = 4096
+
+// chunkFolder folds the nearby tickets into the error rate and the candidate fields of each position a
+// chunk at a time, so that its memory use only depends on the size of the chunks.
+type chunkFolder struct {
+	rules      []Rule
+	tables     []*denseTable[int64] // tables holds the lookup table of each rule, see denseRules.
+	index      validIndex[int64]
+	maxInvalid int
+	size       int
+
+	chunk      []Ticket // chunk holds the tickets added since the last fold.
+	candidates []bitset // candidates holds, by position, the rules matching all the tickets folded so far.
+
+	// columns and values are the scratch space the valid tickets of a chunk are transposed into.
+	columns [][]int64
+	values  []int64
+
+	progress *progressCounter // progress counts the tickets folded.
+
+	errorRate         int64
+	errorRateOverflow bool     // errorRateOverflow tells that errorRate wrapped around.
+	errorRateBig      *big.Int // errorRateBig is only summed when it isn't nil.
+	valid             int
+	invalid           int
+	tolerated         int
+}
+
+// newChunkFolder creates a folder checking the tickets against the rules, tolerating the tickets holding
+// up to maxInvalid invalid values, and folding the tickets size at a time.
+func newChunkFolder(rules []Rule, maxInvalid int, size int) *chunkFolder {
+	if size < 1 {
+		size = DefaultChunkSize
+	}
+
+	return &chunkFolder{
+		rules:      rules,
+		tables:     denseRules(rules),
+		index:      newValidIndex(rules),
+		maxInvalid: maxInvalid,
+		size:       size,
+		chunk:      make([]Ticket, 0, size),
+	}
+}
+
+// add adds a nearby ticket, folding the chunk once it is full.
+func (f *chunkFolder) add(ticket Ticket) error {
+	f.chunk = append(f.chunk, ticket)
+	if len(f.chunk) < f.size {
+		return nil
+	}
+
+	return f.fold()
+}
+
+// fold adds the invalid values of the chunk to the error rate, and rules out the candidates its valid and
+// tolerated tickets don't match. The valid tickets are checked a position at a time, each rule only until
+// a value doesn't match it, which leaves little to check once the candidates have narrowed.
+func (f *chunkFolder) fold() error {
+	valid := f.chunk[:0]
+	for _, ticket := range f.chunk {
+		if err := f.fit(ticket); err != nil {
+			return err
+		}
+
+		if f.index.validTicket(ticket) {
+			valid = append(valid, ticket)
+			continue
+		}
+
+		invalids := explainTicket(ticket, f.rules, f.index, CollectAll)
+		for _, invalid := range invalids {
+			var wrapped bool
+			f.errorRate, wrapped = addInt64(f.errorRate, invalid.Value)
+			f.errorRateOverflow = f.errorRateOverflow || wrapped
+			if f.errorRateBig != nil {
+				f.errorRateBig.Add(f.errorRateBig, big.NewInt(invalid.Value))
+			}
+		}
+
+		if len(invalids) > f.maxInvalid {
+			f.invalid++
+			continue
+		}
+
+		f.tolerated++
+		f.narrow(ticket, invalids)
+	}
+	f.valid += len(valid)
+
+	if len(valid) > 0 {
+		f.columns, f.values = transposeInto(len(f.candidates), valid, f.columns, f.values)
+		for fieldPos, column := range f.columns {
+			row := f.candidates[fieldPos]
+			for idx := row.next(0); idx >= 0; idx = row.next(idx + 1) {
+				for _, value := range column {
+					if !matchesDense(f.rules[idx], f.tables[idx], value) {
+						row.clear(idx)
+						break
+					}
+				}
+			}
+		}
+	}
+
+	f.progress.add(len(f.chunk))
+
+	// The tickets of the chunk aren't needed anymore, let them be collected.
+	for idx := range f.chunk {
+		f.chunk[idx] = Ticket{}
+	}
+	f.chunk = f.chunk[:0]
+
+	return nil
+}
+
+// fit checks that the ticket has as many values as the ones before. The first ticket tells how many
+// positions there are, all the rules being candidates.
+func (f *chunkFolder) fit(ticket Ticket) error {
+	if f.candidates == nil {
+		f.candidates = make([]bitset, len(ticket.Values))
+		for fieldPos := range f.candidates {
+			f.candidates[fieldPos] = newBitset(len(f.rules))
+			for idx := range f.rules {
+				f.candidates[fieldPos].set(idx)
+			}
+		}
+
+		return nil
+	}
+
+	if len(ticket.Values) != len(f.candidates) {
+		return fmt.Errorf("ticket has %d values instead of %d", len(ticket.Values), len(f.candidates))
+	}
+
+	return nil
+}
+
+// narrow rules out the candidates that the ticket doesn't match on its own, leaving out the positions of
+// its invalid values and its unknown values, like narrowTicket.
+func (f *chunkFolder) narrow(ticket Ticket, invalids []InvalidValue) {
+	for fieldPos, value := range ticket.Values {
+		if !ticket.Known(fieldPos) || invalidAt(invalids, fieldPos) {
+			continue
+		}
+
+		row := f.candidates[fieldPos]
+		for idx := row.next(0); idx >= 0; idx = row.next(idx + 1) {
+			if !matchesDense(f.rules[idx], f.tables[idx], value) {
+				row.clear(idx)
+			}
+		}
+	}
+}
+
+// matrix returns the candidates folded so far in the layout of candidateMatrix.
+func (f *chunkFolder) matrix() [][]bool {
+	matrix := make([][]bool, len(f.candidates))
+	for fieldPos, row := range f.candidates {
+		matrix[fieldPos] = make([]bool, len(f.rules))
+		for idx := row.next(0); idx >= 0; idx = row.next(idx + 1) {
+			matrix[fieldPos][idx] = true
+		}
+	}
+
+	return matrix
+}
@@ -0,0 +1,151 @@
+package ticket16
+
+import (
+	"context"
+	"sync"
+)
+
+// minComponentPositions is the number of positions from which the ordering is inferred a component at a
+// time. The notes of the puzzle are too small for it to pay off.
+const minComponentPositions = 64
+
+// component gathers the positions and the fields that are connected by the candidates: a position of the
+// component can only hold a field of the component, so that its ordering doesn't depend on the others.
+type component struct {
+	positions []int
+	fields    []int
+}
+
+// components splits the positions and the fields into the connected components of the candidates, in the
+// order of their first position. The fields that no position may hold belong to no component, see
+// leftOut.
+func components(candidates [][]bool, fields int) []component {
+	// The positions are the nodes 0 to len(candidates)-1, and the fields the nodes after them.
+	parent := make([]int, len(candidates)+fields)
+	for node := range parent {
+		parent[node] = node
+	}
+
+	var find func(node int) int
+	find = func(node int) int {
+		if parent[node] != node {
+			parent[node] = find(parent[node])
+		}
+
+		return parent[node]
+	}
+
+	for fieldPos, row := range candidates {
+		for idx, candidate := range row {
+			if candidate {
+				parent[find(len(candidates)+idx)] = find(fieldPos)
+			}
+		}
+	}
+
+	var comps []component
+	byRoot := make(map[int]int)
+	for fieldPos := range candidates {
+		root := find(fieldPos)
+		if _, ok := byRoot[root]; !ok {
+			byRoot[root] = len(comps)
+			comps = append(comps, component{})
+		}
+
+		comp := &comps[byRoot[root]]
+		comp.positions = append(comp.positions, fieldPos)
+	}
+
+	for idx := 0; idx < fields; idx++ {
+		if comp, ok := byRoot[find(len(candidates)+idx)]; ok {
+			comps[comp].fields = append(comps[comp].fields, idx)
+		}
+	}
+
+	return comps
+}
+
+// inferComponents runs the ordering algorithm on each component on its own, up to the parallelism of the
+// solver at the same time, and puts their orderings together. When a component can't be solved, all the
+// candidates are solved again at once, so that the error names the positions and the fields as they are.
+// So are they when the components leave fields out, which would otherwise be dropped from the ordering.
+// The components add the positions they assign to the same progress.
+func (s *Solver) inferComponents(ctx context.Context, candidates [][]bool, fields []string, comps []component, progress *progressCounter) ([]string, []bool, error) {
+	if leftOut(comps, len(fields)) {
+		return s.runAlgorithm(ctx, candidates, fields, progress)
+	}
+
+	orderings := make([][]string, len(comps))
+	forced := make([][]bool, len(comps))
+	errs := make([]error, len(comps))
+
+	semaphore := make(chan struct{}, s.workers())
+	var wg sync.WaitGroup
+	for idx, comp := range comps {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(idx int, comp component) {
+			defer wg.Done()
+			sub, subFields := comp.candidates(candidates, fields)
+			orderings[idx], forced[idx], errs[idx] = s.runAlgorithm(ctx, sub, subFields, progress)
+			<-semaphore
+		}(idx, comp)
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	for _, err := range errs {
+		if err != nil {
+			progress.restart()
+			return s.runAlgorithm(ctx, candidates, fields, progress)
+		}
+	}
+
+	ordering := make([]string, len(candidates))
+	allForced := make([]bool, len(candidates))
+	for idx, comp := range comps {
+		for sub, fieldPos := range comp.positions {
+			ordering[fieldPos] = orderings[idx][sub]
+			allForced[fieldPos] = forced[idx][sub]
+		}
+	}
+
+	return ordering, allForced, nil
+}
+
+// leftOut tells whether some fields can't be given a position by the components: either they belong to
+// none, or their component has more fields than positions. There is no ordering then, like when all the
+// candidates are solved at once.
+func leftOut(comps []component, fields int) bool {
+	count := 0
+	for _, comp := range comps {
+		if len(comp.fields) > len(comp.positions) {
+			return true
+		}
+		count += len(comp.fields)
+	}
+
+	return count < fields
+}
+
+// candidates returns the candidates of the positions of the component among its fields, and the names of
+// these fields.
+func (c component) candidates(candidates [][]bool, fields []string) ([][]bool, []string) {
+	sub := make([][]bool, len(c.positions))
+	for row, fieldPos := range c.positions {
+		sub[row] = make([]bool, len(c.fields))
+		for col, idx := range c.fields {
+			sub[row][col] = candidates[fieldPos][idx]
+		}
+	}
+
+	names := make([]string, len(c.fields))
+	for col, idx := range c.fields {
+		names[col] = fields[idx]
+	}
+
+	return sub, names
+}
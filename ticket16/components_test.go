@@ -0,0 +1,74 @@
+package ticket16
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// wideCandidates returns the candidates of positions positions each holding the field of the same index,
+// among fields fields, and the names of the fields.
+func wideCandidates(positions, fields int) ([][]bool, []string) {
+	candidates := make([][]bool, positions)
+	for fieldPos := range candidates {
+		candidates[fieldPos] = make([]bool, fields)
+		if fieldPos < fields {
+			candidates[fieldPos][fieldPos] = true
+		}
+	}
+
+	names := make([]string, fields)
+	for idx := range names {
+		names[idx] = fmt.Sprintf("field %d", idx)
+	}
+
+	return candidates, names
+}
+
+func TestComponentsLeaveNoFieldOut(t *testing.T) {
+	for _, positions := range []int{minComponentPositions - 1, minComponentPositions} {
+		for _, algorithm := range algorithms {
+			t.Run(fmt.Sprintf("%d positions/%s", positions, algorithm), func(t *testing.T) {
+				// The last field may be held by no position.
+				candidates, fields := wideCandidates(positions, positions+1)
+
+				_, _, _, err := NewSolver(WithAlgorithm(algorithm)).inferOrdering(context.Background(), candidates, fields)
+				var unsolvable *UnsolvableError
+				if !errors.As(err, &unsolvable) {
+					t.Fatalf("inferOrdering error = %v, want an UnsolvableError", err)
+				}
+				if want := fields[positions:]; !reflect.DeepEqual(unsolvable.Fields, want) {
+					t.Errorf("unsolvable fields = %q, want %q", unsolvable.Fields, want)
+				}
+			})
+		}
+	}
+}
+
+func TestComponentsRestartProgress(t *testing.T) {
+	// The last two positions may only hold the last field, which fails their component after the others
+	// are solved.
+	positions := minComponentPositions + 2
+	candidates, fields := wideCandidates(positions, positions-1)
+	candidates[positions-1][positions-2] = true
+
+	reports := 0
+	solver := NewSolver(WithProgress(func(progress Progress) {
+		if progress.Done > progress.Total {
+			t.Errorf("progress done = %d, over the total of %d", progress.Done, progress.Total)
+		}
+		reports++
+	}))
+
+	_, _, _, err := solver.inferOrdering(context.Background(), candidates, fields)
+	if !errors.Is(err, ErrUnsolvable) {
+		t.Fatalf("inferOrdering error = %v, want %v", err, ErrUnsolvable)
+	}
+	// The components report once for each position they assign, all but the last, and the fallback at
+	// least once more.
+	if reports < positions {
+		t.Errorf("%d progress reports, want the fallback to report after the %d of the components", reports, positions-1)
+	}
+}
@@ -0,0 +1,121 @@
+package ticket16
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// CSVOptions controls how tickets are read from CSV.
+type CSVOptions struct {
+	// Header tells that the first row holds the 0-based ticket position of each column instead of values.
+	// It allows the columns of a spreadsheet to be in any order. Without header, column N is position N.
+	Header bool
+
+	// PrefixedLiterals allows the values to be written in hexadecimal (0x1F) or binary (0b1010) besides
+	// decimal, as in ParseOptions.
+	PrefixedLiterals bool
+
+	// UnknownValues allows the cells to be "?", or empty, when their value couldn't be read, as in
+	// ParseOptions.
+	UnknownValues bool
+
+	// Ragged tells what to do with the rows whose number of columns differs from the first row. With
+	// RaggedSkip, they are read as they are, for the solver to leave them out. It defaults to RaggedError.
+	Ragged RaggedPolicy
+}
+
+// DecodeTicketsCSV reads tickets from CSV, one ticket per row. All the rows must have the same number of
+// columns, unless CSVOptions.Ragged says otherwise. Problems are reported as ParseError with the line and
+// column of the offending cell.
+func DecodeTicketsCSV(r io.Reader, opts CSVOptions) ([]Ticket, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.ReuseRecord = true
+	if opts.Ragged == RaggedSkip {
+		reader.FieldsPerRecord = -1
+	}
+
+	var positions []int
+	tickets := make([]Ticket, 0)
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var csvErr *csv.ParseError
+			if errors.As(err, &csvErr) {
+				return nil, &ParseError{Line: csvErr.Line, Column: csvErr.Column, Msg: csvErr.Err.Error()}
+			}
+
+			return nil, err
+		}
+
+		if opts.Header && positions == nil {
+			positions, err = csvPositions(reader, record)
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		values := make([]int64, len(record))
+		var unknown []bool
+		for col, cell := range record {
+			position := col
+			if positions != nil && len(record) == len(positions) {
+				position = positions[col]
+			}
+
+			if opts.UnknownValues && isUnknown(strings.TrimSpace(cell)) {
+				if unknown == nil {
+					unknown = make([]bool, len(record))
+				}
+				unknown[position] = true
+				continue
+			}
+
+			value, err := parseValue(strings.TrimSpace(cell), ParseOptions{PrefixedLiterals: opts.PrefixedLiterals})
+			if err != nil {
+				line, column := reader.FieldPos(col)
+				return nil, &ParseError{Line: line, Column: column, Msg: fmt.Sprintf("ticket value '%s' %s", cell, invalidIntReason(err))}
+			}
+
+			values[position] = value
+		}
+
+		tickets = append(tickets, Ticket{Values: values, Unknown: unknown})
+	}
+
+	return tickets, nil
+}
+
+// csvPositions reads the header row. Every column must name a distinct position, between 0 and the
+// number of columns (exclusive).
+func csvPositions(reader *csv.Reader, header []string) ([]int, error) {
+	positions := make([]int, len(header))
+	seen := make(map[int]bool, len(header))
+
+	for col, cell := range header {
+		line, column := reader.FieldPos(col)
+
+		position, err := strconv.Atoi(strings.TrimSpace(cell))
+		if err != nil || position < 0 || position >= len(header) {
+			return nil, &ParseError{Line: line, Column: column, Msg: fmt.Sprintf("header '%s' is not a position between 0 and %d", cell, len(header)-1)}
+		}
+
+		if seen[position] {
+			return nil, &ParseError{Line: line, Column: column, Msg: fmt.Sprintf("header position %d appears more than once", position)}
+		}
+
+		positions[col] = position
+		seen[position] = true
+	}
+
+	return positions, nil
+}
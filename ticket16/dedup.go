@@ -0,0 +1,71 @@
+package ticket16
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// DuplicateStats tells how many of the tickets the ordering was inferred from were exact duplicates, see
+// WithDedup.
+type DuplicateStats struct {
+	Unique     int // Unique counts the distinct tickets.
+	Duplicates int // Duplicates counts the tickets identical to one seen before, which were collapsed.
+	MaxCount   int // MaxCount is the number of times the most repeated ticket was seen.
+}
+
+// String implements the fmt.Stringer interface, e.g. "12 collapsed, 190 unique, seen up to 3 times".
+func (s DuplicateStats) String() string {
+	return fmt.Sprintf("%d collapsed, %d unique, seen up to %d times", s.Duplicates, s.Unique, s.MaxCount)
+}
+
+// dedupTickets collapses the identical tickets, keeping the first of each in the order of the tickets. It
+// returns the tickets kept and the number of times each was seen.
+func dedupTickets(tickets []Ticket) ([]Ticket, []int) {
+	unique := make([]Ticket, 0, len(tickets))
+	var counts []int
+	seen := make(map[string]int, len(tickets))
+
+	key := make([]byte, 0, 64)
+	for _, ticket := range tickets {
+		key = appendTicketKey(key[:0], ticket)
+		if idx, ok := seen[string(key)]; ok {
+			counts[idx]++
+			continue
+		}
+
+		seen[string(key)] = len(unique)
+		unique = append(unique, ticket)
+		counts = append(counts, 1)
+	}
+
+	return unique, counts
+}
+
+// appendTicketKey appends the values of the ticket to the key as varints, so that two tickets have the same
+// key when they hold the same values.
+func appendTicketKey(key []byte, ticket Ticket) []byte {
+	for fieldPos, value := range ticket.Values {
+		// A marker tells the unknown values apart from the known ones.
+		if !ticket.Known(fieldPos) {
+			key = append(key, 0)
+			continue
+		}
+
+		key = binary.AppendVarint(append(key, 1), value)
+	}
+
+	return key
+}
+
+// duplicateStats sums up the numbers of times the tickets kept by dedupTickets were seen.
+func duplicateStats(counts []int) *DuplicateStats {
+	stats := &DuplicateStats{Unique: len(counts)}
+	for _, count := range counts {
+		stats.Duplicates += count - 1
+		if count > stats.MaxCount {
+			stats.MaxCount = count
+		}
+	}
+
+	return stats
+}
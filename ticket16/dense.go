@@ -0,0 +1,86 @@
+package ticket16
+
+// DenseDomainLimit is the widest span of values, from the lowest to the highest bound of a set of ranges,
+// that is checked with a lookup table instead of a binary search.
+const DenseDomainLimit = 1 << 16
+
+// denseTable tells whether a value is in a set of integer ranges with a single bit test. It only covers
+// the values from the lowest to the highest bound of the set, the others are not in it.
+type denseTable[T Number] struct {
+	lo, hi T
+	span   uint64 // span is hi-lo.
+	bits   bitset // bits holds a spare bit past the span, never set, for miss.
+}
+
+// newDenseTable builds the lookup table of the set, or returns nil when the set doesn't hold integers,
+// is unbounded or spans DenseDomainLimit values or more.
+func newDenseTable[T Number](set RangeSetOf[T]) *denseTable[T] {
+	if !isInteger[T]() || len(set.ranges) == 0 {
+		return nil
+	}
+
+	first, last := set.ranges[0], set.ranges[len(set.ranges)-1]
+	if first.NoMin || last.NoMax {
+		return nil
+	}
+
+	// The span of the signed integers wraps around when it doesn't fit in T.
+	lo, hi := first.Min, last.Max
+	if hi-lo < 0 || uint64(hi-lo) >= DenseDomainLimit {
+		return nil
+	}
+
+	table := &denseTable[T]{lo: lo, hi: hi, span: uint64(hi - lo), bits: newBitset(int(hi-lo) + 2)}
+	for _, rng := range set.ranges {
+		for value := rng.Min; ; value++ {
+			table.bits.set(int(value - lo))
+			if value == rng.Max {
+				break
+			}
+		}
+	}
+
+	return table
+}
+
+// contains tells whether the value is in the set.
+func (t *denseTable[T]) contains(value T) bool {
+	return value >= t.lo && value <= t.hi && t.bits.has(int(value-t.lo))
+}
+
+// miss returns 1 when the value is not in the set, and 0 otherwise, with a single comparison: the values
+// below lo wrap around to offsets past the span, which all test the spare bit.
+func (t *denseTable[T]) miss(value T) uint64 {
+	offset := uint64(value - t.lo)
+	if offset > t.span {
+		offset = t.span + 1
+	}
+
+	return ^t.bits[offset/64] >> (offset % 64) & 1
+}
+
+// denseRules builds the lookup table of each rule, as compiled by compileRules. The custom rules, and
+// the configurations that can't have one, are left nil, see newDenseTable.
+func denseRules[T Number](rules []RuleOf[T]) []*denseTable[T] {
+	tables := make([]*denseTable[T], len(rules))
+	if !isInteger[T]() {
+		return tables
+	}
+
+	for idx, rule := range rules {
+		if config, ok := rule.(compiledConfig[T]); ok {
+			tables[idx] = newDenseTable(config.ranges.subtract(config.exclusions))
+		}
+	}
+
+	return tables
+}
+
+// matchesDense tells whether the rule matches the value, with its lookup table when it has one.
+func matchesDense[T Number](rule RuleOf[T], table *denseTable[T], value T) bool {
+	if table != nil {
+		return table.contains(value)
+	}
+
+	return rule.Matches(value)
+}
@@ -0,0 +1,140 @@
+package ticket16
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// notesDocument is the structured (JSON and YAML) representation of the notes.
+type notesDocument struct {
+	Rules         []ruleDocument `json:"rules" yaml:"rules"`
+	YourTicket    []*int64       `json:"your_ticket" yaml:"your_ticket"`
+	NearbyTickets [][]*int64     `json:"nearby_tickets" yaml:"nearby_tickets"`
+}
+
+// ruleDocument is the structured representation of a Configuration.
+type ruleDocument struct {
+	Field      string          `json:"field" yaml:"field"`
+	Ranges     []rangeDocument `json:"ranges" yaml:"ranges"`
+	Exclusions []rangeDocument `json:"exclusions,omitempty" yaml:"exclusions,omitempty"`
+}
+
+// rangeDocument is the structured representation of a ValidRange. A missing bound means the range
+// is open-ended on that side, but one of them must be there.
+type rangeDocument struct {
+	Min *int64 `json:"min,omitempty" yaml:"min,omitempty"`
+	Max *int64 `json:"max,omitempty" yaml:"max,omitempty"`
+
+	// text is the range written as a YAML scalar, and line its line in the document. It is parsed by
+	// toNotes, with the options of the decoder.
+	text string
+	line int
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface. Besides the {min: 1, max: 3} mapping,
+// a range may be written as the "1-3" (or "1+", "<=3") scalar used by the puzzle, which is easier
+// to maintain by hand.
+func (r *rangeDocument) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.ScalarNode {
+		// Decode through another type so that this method is not called again.
+		type plainRange rangeDocument
+		return node.Decode((*plainRange)(r))
+	}
+
+	*r = rangeDocument{text: strings.TrimSpace(node.Value), line: node.Line}
+	return nil
+}
+
+// toNotes checks the document and converts it to Notes. The ranges written as scalars are parsed like
+// those of the text format, with the options.
+func (doc *notesDocument) toNotes(opts ParseOptions) (*Notes, error) {
+	notes := &Notes{
+		Configs:       make([]Configuration, len(doc.Rules)),
+		MyTicket:      ticketOf(doc.YourTicket),
+		NearbyTickets: make([]Ticket, len(doc.NearbyTickets)),
+	}
+
+	for idx, rule := range doc.Rules {
+		if rule.Field == "" {
+			return nil, fmt.Errorf("rule %d has no field name", idx)
+		}
+
+		if len(rule.Ranges) == 0 {
+			return nil, fmt.Errorf("rule %q has no ranges", rule.Field)
+		}
+
+		ranges, err := toValidRanges(rule.Ranges, opts)
+		if err != nil {
+			return nil, fmt.Errorf("rule %q: %w", rule.Field, err)
+		}
+
+		exclusions, err := toValidRanges(rule.Exclusions, opts)
+		if err != nil {
+			return nil, fmt.Errorf("rule %q: %w", rule.Field, err)
+		}
+
+		notes.Configs[idx] = Configuration{Field: rule.Field, Ranges: ranges, Exclusions: exclusions}
+	}
+
+	for idx, values := range doc.NearbyTickets {
+		notes.NearbyTickets[idx] = ticketOf(values)
+	}
+
+	return notes, nil
+}
+
+// toValidRanges converts the structured ranges to ValidRanges, parsing the scalar ones with the options.
+// It returns nil when there are no ranges.
+func toValidRanges(docs []rangeDocument, opts ParseOptions) ([]ValidRange, error) {
+	if len(docs) == 0 {
+		return nil, nil
+	}
+
+	ranges := make([]ValidRange, len(docs))
+	for idx, rng := range docs {
+		if rng.text != "" {
+			parsed, err := parseRange(rng.text, 0, opts)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %s", rng.line, err.(*ParseError).Msg)
+			}
+
+			ranges[idx] = parsed
+			continue
+		}
+
+		if rng.Min == nil && rng.Max == nil {
+			return nil, fmt.Errorf("range %d has neither a minimum nor a maximum", idx)
+		}
+
+		ranges[idx] = ValidRange{NoMin: rng.Min == nil, NoMax: rng.Max == nil}
+
+		if rng.Min != nil {
+			ranges[idx].Min = *rng.Min
+		}
+
+		if rng.Max != nil {
+			ranges[idx].Max = *rng.Max
+		}
+	}
+
+	return ranges, nil
+}
+
+// fromValidRange converts a ValidRange to its structured representation.
+func fromValidRange(rng ValidRange) rangeDocument {
+	doc := rangeDocument{}
+
+	if !rng.NoMin {
+		min := rng.Min
+		doc.Min = &min
+	}
+
+	if !rng.NoMax {
+		max := rng.Max
+		doc.Max = &max
+	}
+
+	return doc
+}
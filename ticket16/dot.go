@@ -0,0 +1,112 @@
+package ticket16
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// CandidateGraph is the bipartite graph of the fields and of the positions that WriteDOT draws: an edge
+// joins each field to each position it may be held by, see CandidateMatrix.
+type CandidateGraph struct {
+	Matrix CandidateMatrix
+
+	// Positions tells the field found at each position, whose edge is highlighted, dashed when it was
+	// picked among several, see Result.Positions. It is nil when no ordering was found.
+	Positions []PositionReport
+
+	// Unsolvable tells the positions and the fields that couldn't be matched, which are painted red, when
+	// no ordering was found. It may be nil.
+	Unsolvable *UnsolvableError
+}
+
+// WriteDOT writes the graph in the DOT language of Graphviz, the fields on the left and the positions on
+// the right, e.g. for "dot -Tsvg". The fields left without any position, and the positions left without
+// any field, are painted red like the ones of the Unsolvable error.
+func WriteDOT(w io.Writer, graph CandidateGraph) error {
+	matrix := graph.Matrix
+
+	stuckFields := map[string]bool{}
+	stuckPositions := map[int]bool{}
+	if graph.Unsolvable != nil {
+		for _, field := range graph.Unsolvable.Fields {
+			stuckFields[field] = true
+		}
+		for _, fieldPos := range graph.Unsolvable.Positions {
+			stuckPositions[fieldPos] = true
+		}
+	}
+
+	edges := make([]int, matrix.Positions)
+	for idx, field := range matrix.Fields {
+		count := 0
+		for fieldPos := 0; fieldPos < matrix.Positions; fieldPos++ {
+			if matrix.Candidate(idx, fieldPos) {
+				count++
+				edges[fieldPos]++
+			}
+		}
+
+		if count == 0 {
+			stuckFields[field] = true
+		}
+	}
+	for fieldPos, count := range edges {
+		if count == 0 {
+			stuckPositions[fieldPos] = true
+		}
+	}
+
+	found := map[int]PositionReport{}
+	for _, position := range graph.Positions {
+		found[position.Position] = position
+	}
+
+	bw := bufio.NewWriter(w)
+	bw.WriteString("graph candidates {\n")
+	bw.WriteString("\trankdir=LR;\n")
+	bw.WriteString("\tnode [shape=box];\n\n")
+
+	bw.WriteString("\tsubgraph fields {\n\t\trank=same;\n")
+	for idx, field := range matrix.Fields {
+		fmt.Fprintf(bw, "\t\tf%d [label=%s%s];\n", idx, strconv.Quote(field), stuckStyle(stuckFields[field]))
+	}
+	bw.WriteString("\t}\n\n")
+
+	bw.WriteString("\tsubgraph positions {\n\t\trank=same;\n\t\tnode [shape=ellipse];\n")
+	for fieldPos := 0; fieldPos < matrix.Positions; fieldPos++ {
+		fmt.Fprintf(bw, "\t\tp%d [label=\"position %d\"%s];\n", fieldPos, fieldPos, stuckStyle(stuckPositions[fieldPos]))
+	}
+	bw.WriteString("\t}\n\n")
+
+	for idx, field := range matrix.Fields {
+		for fieldPos := 0; fieldPos < matrix.Positions; fieldPos++ {
+			if !matrix.Candidate(idx, fieldPos) {
+				continue
+			}
+
+			style := ""
+			if position, ok := found[fieldPos]; ok && position.Field == field {
+				style = " [color=blue, penwidth=3]"
+				if !position.Forced {
+					style = " [color=blue, penwidth=3, style=dashed]"
+				}
+			}
+
+			fmt.Fprintf(bw, "\tf%d -- p%d%s;\n", idx, fieldPos, style)
+		}
+	}
+	bw.WriteString("}\n")
+
+	return bw.Flush()
+}
+
+// stuckStyle returns the attributes painting a node red when it is stuck, none otherwise.
+func stuckStyle(stuck bool) string {
+	if !stuck {
+		return ""
+	}
+
+	return ", color=red, fontcolor=red"
+}
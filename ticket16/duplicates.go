@@ -0,0 +1,71 @@
+package ticket16
+
+import "fmt"
+
+// DuplicatePolicy tells what to do when the rules define the same field more than once.
+type DuplicatePolicy string
+
+const (
+	// DuplicateError rejects the notes. It is the default policy.
+	DuplicateError DuplicatePolicy = "error"
+	// DuplicateMerge combines the ranges and the exclusions of all the rules of the field.
+	DuplicateMerge DuplicatePolicy = "merge"
+	// DuplicateKeepFirst keeps the first rule of the field and ignores the others.
+	DuplicateKeepFirst DuplicatePolicy = "keep-first"
+)
+
+// ParseDuplicatePolicy checks the name of a duplicate policy, as written on the command line.
+func ParseDuplicatePolicy(name string) (DuplicatePolicy, error) {
+	switch policy := DuplicatePolicy(name); policy {
+	case DuplicateError, DuplicateMerge, DuplicateKeepFirst:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unknown duplicate rule policy %q", name)
+	}
+}
+
+// addConfiguration adds the config to the configs, applying the policy when a config with the same
+// field already exists. It returns the updated configs and the index of the existing config, or -1
+// when the field is new.
+func addConfiguration(configs []Configuration, config Configuration, policy DuplicatePolicy) ([]Configuration, int, error) {
+	existingIdx := -1
+	for idx, existing := range configs {
+		if existing.Field == config.Field {
+			existingIdx = idx
+			break
+		}
+	}
+
+	if existingIdx < 0 {
+		return append(configs, config), -1, nil
+	}
+
+	switch policy {
+	case DuplicateError, "":
+		return configs, existingIdx, fmt.Errorf("rule %q is defined more than once", config.Field)
+	case DuplicateMerge:
+		existing := &configs[existingIdx]
+		existing.Ranges = append(existing.Ranges, config.Ranges...)
+		existing.Exclusions = append(existing.Exclusions, config.Exclusions...)
+	case DuplicateKeepFirst:
+		// Nothing to do, the first one is already there.
+	default:
+		return configs, existingIdx, fmt.Errorf("unknown duplicate rule policy %q", policy)
+	}
+
+	return configs, existingIdx, nil
+}
+
+// resolveDuplicates applies the policy to all the configs, in order.
+func resolveDuplicates(configs []Configuration, policy DuplicatePolicy) ([]Configuration, error) {
+	resolved := make([]Configuration, 0, len(configs))
+
+	for _, config := range configs {
+		var err error
+		if resolved, _, err = addConfiguration(resolved, config, policy); err != nil {
+			return nil, err
+		}
+	}
+
+	return resolved, nil
+}
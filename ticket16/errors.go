@@ -0,0 +1,149 @@
+package ticket16
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// The errors.Is targets telling the kind of a solving error apart, whatever its details.
+var (
+	// ErrParse matches every ParseError: the notes are malformed.
+	ErrParse = errors.New("malformed notes")
+
+	// ErrUnsolvable matches every UnsolvableError: no field can be assigned to every position.
+	ErrUnsolvable = errors.New("no consistent field ordering")
+
+	// ErrAmbiguousOrdering matches every AmbiguousOrderingError: several orderings are possible.
+	ErrAmbiguousOrdering = errors.New("ambiguous field ordering")
+
+	// ErrInvalidMyTicket matches every InvalidMyTicketError: your ticket holds values matching no rule.
+	ErrInvalidMyTicket = errors.New("invalid values in your ticket")
+
+	// ErrCheckpointMismatch tells that the notes don't start with the nearby tickets of the checkpoint they
+	// were resumed from, see Solver.ResumeStream.
+	ErrCheckpointMismatch = errors.New("the notes don't start with the tickets of the checkpoint")
+)
+
+// ParseError describes a malformed piece of the notes. It records where the problem was found so that
+// the user can fix the input quickly.
+type ParseError struct {
+	Line   int    // Line is the 1-based line number of the offending line, 0 when unknown.
+	Column int    // Column is the 1-based column of the offending text inside the line, 0 when unknown.
+	Text   string // Text is the raw content of the offending line.
+	Msg    string // Msg describes what is wrong.
+}
+
+// Error implements the error interface. The format is "line <n>, column <c>: <msg>: <text>", where the
+// parts that are unknown are left out.
+func (e *ParseError) Error() string {
+	location := make([]string, 0, 2)
+
+	if e.Line > 0 {
+		location = append(location, fmt.Sprintf("line %d", e.Line))
+	}
+
+	if e.Column > 0 {
+		location = append(location, fmt.Sprintf("column %d", e.Column))
+	}
+
+	msg := e.Msg
+	if len(location) > 0 {
+		msg = strings.Join(location, ", ") + ": " + msg
+	}
+
+	if e.Text != "" {
+		msg = fmt.Sprintf("%s: %q", msg, e.Text)
+	}
+
+	return msg
+}
+
+// Is tells that a ParseError matches ErrParse.
+func (e *ParseError) Is(target error) bool {
+	return target == ErrParse
+}
+
+// UnsolvableError tells that the valid tickets can't be ordered: some positions match none of the fields
+// left, some fields match none of the positions left, or some positions only match too few fields to each
+// get a different one.
+type UnsolvableError struct {
+	Positions []int    // Positions lists the 0-based positions that could not be given a field.
+	Fields    []string // Fields lists the fields that could not be assigned to a position.
+}
+
+// Error implements the error interface.
+func (e *UnsolvableError) Error() string {
+	if len(e.Positions) == 0 {
+		return fmt.Sprintf("%s: no position left for the fields %q", ErrUnsolvable, e.Fields)
+	}
+
+	return fmt.Sprintf("%s: positions %v can't be given any of the fields %q", ErrUnsolvable, e.Positions, e.Fields)
+}
+
+// Is tells that an UnsolvableError matches ErrUnsolvable.
+func (e *UnsolvableError) Is(target error) bool {
+	return target == ErrUnsolvable
+}
+
+// AmbiguousOrderingError tells that the valid tickets don't tell the fields apart: every position left
+// matches several fields.
+type AmbiguousOrderingError struct {
+	Positions  []int      // Positions lists the 0-based positions that can't be told apart.
+	Candidates [][]string // Candidates lists the fields matching each of the Positions.
+}
+
+// Error implements the error interface.
+func (e *AmbiguousOrderingError) Error() string {
+	details := make([]string, len(e.Positions))
+	for idx, pos := range e.Positions {
+		details[idx] = fmt.Sprintf("position %d may be %q", pos, e.Candidates[idx])
+	}
+
+	return fmt.Sprintf("%s: %s", ErrAmbiguousOrdering, strings.Join(details, ", "))
+}
+
+// Is tells that an AmbiguousOrderingError matches ErrAmbiguousOrdering.
+func (e *AmbiguousOrderingError) Is(target error) bool {
+	return target == ErrAmbiguousOrdering
+}
+
+// InvalidMyTicketError tells that some values of your ticket match none of the rules, which usually means
+// a typo in the notes. Such a value can't be trusted in part 2, nor can the ordering inferred with it.
+type InvalidMyTicketError struct {
+	Values []InvalidValue // Values lists the values matching no rule, in the order of the ticket.
+}
+
+// Error implements the error interface.
+func (e *InvalidMyTicketError) Error() string {
+	details := make([]string, len(e.Values))
+	for idx, value := range e.Values {
+		details[idx] = value.String()
+	}
+
+	return fmt.Sprintf("%s: %s", ErrInvalidMyTicket, strings.Join(details, ", "))
+}
+
+// Is tells that an InvalidMyTicketError matches ErrInvalidMyTicket.
+func (e *InvalidMyTicketError) Is(target error) bool {
+	return target == ErrInvalidMyTicket
+}
+
+// newParseError creates a ParseError pointing to the given 0-based offset inside the line.
+func newParseError(offset int, format string, args ...interface{}) *ParseError {
+	return &ParseError{
+		Column: offset + 1,
+		Msg:    fmt.Sprintf(format, args...),
+	}
+}
+
+// atLine attaches the line number and the raw line to the error if it is a ParseError.
+// Any other error is returned untouched.
+func atLine(err error, line int, text string) error {
+	if parseErr, ok := err.(*ParseError); ok {
+		parseErr.Line = line
+		parseErr.Text = text
+	}
+
+	return err
+}
@@ -0,0 +1,10 @@
+package ticket16
+
+import (
+	_ "embed"
+)
+
+// ExampleInput holds the sample notes given in the puzzle statement. Its error rate is 71.
+//
+//go:embed example.txt
+var ExampleInput string
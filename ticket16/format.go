@@ -0,0 +1,80 @@
+package ticket16
+
+import (
+	"fmt"
+	"io"
+)
+
+// Format identifies the notation the notes are written in.
+type Format string
+
+const (
+	// FormatText is the notation used by the puzzle itself.
+	FormatText Format = "text"
+	// FormatJSON is the notation read by DecodeNotesJSON.
+	FormatJSON Format = "json"
+	// FormatYAML is the notation read by DecodeNotesYAML.
+	FormatYAML Format = "yaml"
+	// FormatCSV is the notation read by DecodeTicketsCSV. It only holds nearby tickets, so the rules
+	// have to come from a separate document.
+	FormatCSV Format = "csv"
+	// FormatProto is the binary protocol buffers encoding read by DecodeNotesProto.
+	FormatProto Format = "proto"
+)
+
+// DecodeNotes reads the notes written in the given format. Only the Duplicates parse option applies to
+// all the formats, the others only apply to the text format.
+func DecodeNotes(r io.Reader, format Format, opts ParseOptions) (*Notes, error) {
+	var notes *Notes
+	var err error
+
+	switch format {
+	case FormatText:
+		return ParseNotes(r, opts)
+	case FormatJSON:
+		notes, err = decodeNotesJSON(r, opts)
+	case FormatYAML:
+		notes, err = decodeNotesYAML(r, opts)
+	case FormatProto:
+		notes, err = DecodeNotesProto(r)
+	case FormatCSV:
+		return nil, fmt.Errorf("%q input only holds tickets, the rules must come from a separate document", format)
+	default:
+		return nil, fmt.Errorf("unknown input format %q", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if notes.Configs, err = resolveDuplicates(notes.Configs, opts.Duplicates); err != nil {
+		return nil, err
+	}
+
+	return notes, nil
+}
+
+// DecodeRules reads a rules document written in the given format. Unlike DecodeNotes, the tickets are
+// optional in the text format.
+func DecodeRules(r io.Reader, format Format, opts ParseOptions) (*Notes, error) {
+	if format == FormatText {
+		return ParseRules(r, opts)
+	}
+
+	return DecodeNotes(r, format, opts)
+}
+
+// DecodeTickets reads a ticket dump written in the given format. For the structured formats, the
+// nearby tickets of the document are returned. CSV dumps are read by DecodeTicketsCSV instead.
+// The problems recovered from are returned as warnings.
+func DecodeTickets(r io.Reader, format Format, opts ParseOptions) ([]Ticket, []*ParseError, error) {
+	if format == FormatText {
+		return ParseTickets(r, opts)
+	}
+
+	notes, err := DecodeNotes(r, format, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return notes.NearbyTickets, notes.Warnings, nil
+}
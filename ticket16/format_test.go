@@ -0,0 +1,178 @@
+package ticket16
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// roundTripNotes are notes using what each format has to carry: open ranges, exclusions, values below
+// zero and unknown values.
+const roundTripNotes = `departure location: 1-3 or 5-7 not 2-2
+row: <=11 or 33-44
+"seat: left": >=13 not 40-45
+
+your ticket:
+7,1,14
+
+nearby tickets:
+7,3,47
+40,?,50
+-5,55,2
+`
+
+// roundTripOptions read the unknown values of roundTripNotes.
+var roundTripOptions = ParseOptions{UnknownValues: true}
+
+// encodeJSON writes the notes as the JSON document read by DecodeNotesJSON, from the JSON encoding of the
+// core types.
+func encodeJSON(t *testing.T, notes *Notes) []byte {
+	t.Helper()
+
+	data, err := json.Marshal(map[string]any{
+		"rules":          notes.Configs,
+		"your_ticket":    notes.MyTicket,
+		"nearby_tickets": notes.NearbyTickets,
+	})
+	if err != nil {
+		t.Fatalf("json.Marshal failed: %s", err)
+	}
+
+	return data
+}
+
+// encodeYAML writes the notes as the YAML document read by DecodeNotesYAML, the ranges as scalars.
+func encodeYAML(t *testing.T, notes *Notes) []byte {
+	t.Helper()
+
+	type rule struct {
+		Field      string   `yaml:"field"`
+		Ranges     []string `yaml:"ranges"`
+		Exclusions []string `yaml:"exclusions,omitempty"`
+	}
+	scalars := func(ranges []ValidRange) []string {
+		texts := make([]string, len(ranges))
+		for idx, rng := range ranges {
+			texts[idx] = rng.String()
+		}
+
+		return texts
+	}
+	values := func(ticket Ticket) []*int64 {
+		values := make([]*int64, len(ticket.Values))
+		for fieldPos := range values {
+			if ticket.Known(fieldPos) {
+				values[fieldPos] = &ticket.Values[fieldPos]
+			}
+		}
+
+		return values
+	}
+
+	doc := struct {
+		Rules         []rule     `yaml:"rules"`
+		YourTicket    []*int64   `yaml:"your_ticket"`
+		NearbyTickets [][]*int64 `yaml:"nearby_tickets"`
+	}{YourTicket: values(notes.MyTicket)}
+	for _, config := range notes.Configs {
+		doc.Rules = append(doc.Rules, rule{Field: config.Field, Ranges: scalars(config.Ranges), Exclusions: scalars(config.Exclusions)})
+	}
+	for _, ticket := range notes.NearbyTickets {
+		doc.NearbyTickets = append(doc.NearbyTickets, values(ticket))
+	}
+
+	data, err := yaml.Marshal(doc)
+	if err != nil {
+		t.Fatalf("yaml.Marshal failed: %s", err)
+	}
+
+	return data
+}
+
+// encodeCSV writes the nearby tickets of the notes as CSV, the unknown values as "?".
+func encodeCSV(t *testing.T, notes *Notes) []byte {
+	t.Helper()
+
+	var data bytes.Buffer
+	writer := csv.NewWriter(&data)
+	for _, ticket := range notes.NearbyTickets {
+		record := make([]string, len(ticket.Values))
+		for fieldPos, value := range ticket.Values {
+			record[fieldPos] = "?"
+			if ticket.Known(fieldPos) {
+				record[fieldPos] = strconv.FormatInt(value, 10)
+			}
+		}
+		writer.Write(record)
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		t.Fatalf("csv.Writer failed: %s", err)
+	}
+
+	return data.Bytes()
+}
+
+func TestFormatRoundTrip(t *testing.T) {
+	want, err := ParseNotes(strings.NewReader(roundTripNotes), roundTripOptions)
+	if err != nil {
+		t.Fatalf("ParseNotes failed: %s", err)
+	}
+
+	encoders := []struct {
+		format Format
+		encode func(t *testing.T, notes *Notes) []byte
+	}{
+		{FormatText, func(t *testing.T, notes *Notes) []byte {
+			var text bytes.Buffer
+			if err := WriteNotes(&text, notes); err != nil {
+				t.Fatalf("WriteNotes failed: %s", err)
+			}
+
+			return text.Bytes()
+		}},
+		{FormatJSON, encodeJSON},
+		{FormatYAML, encodeYAML},
+		{FormatProto, func(t *testing.T, notes *Notes) []byte { return MarshalNotesProto(notes) }},
+	}
+
+	for _, encoder := range encoders {
+		t.Run(string(encoder.format), func(t *testing.T) {
+			data := encoder.encode(t, want)
+
+			got, err := DecodeNotes(bytes.NewReader(data), encoder.format, roundTripOptions)
+			if err != nil {
+				t.Fatalf("DecodeNotes failed: %s\n%s", err, data)
+			}
+
+			if !reflect.DeepEqual(got.Configs, want.Configs) {
+				t.Errorf("rules = %v, want %v", got.Configs, want.Configs)
+			}
+			if !reflect.DeepEqual(got.MyTicket, want.MyTicket) {
+				t.Errorf("your ticket = %v, want %v", got.MyTicket, want.MyTicket)
+			}
+			if !reflect.DeepEqual(got.NearbyTickets, want.NearbyTickets) {
+				t.Errorf("nearby tickets = %v, want %v", got.NearbyTickets, want.NearbyTickets)
+			}
+		})
+	}
+
+	t.Run(string(FormatCSV), func(t *testing.T) {
+		data := encodeCSV(t, want)
+
+		got, err := DecodeTicketsCSV(bytes.NewReader(data), CSVOptions{UnknownValues: true})
+		if err != nil {
+			t.Fatalf("DecodeTicketsCSV failed: %s\n%s", err, data)
+		}
+
+		if !reflect.DeepEqual(got, want.NearbyTickets) {
+			t.Errorf("nearby tickets = %v, want %v", got, want.NearbyTickets)
+		}
+	})
+}
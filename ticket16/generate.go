@@ -0,0 +1,141 @@
+package ticket16
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+)
+
+// GenerateOptions controls the synthetic notes produced by GenerateNotes.
+type GenerateOptions struct {
+	Fields      int     // Fields is the number of fields (and so of ticket positions).
+	Tickets     int     // Tickets is the number of nearby tickets.
+	InvalidRate float64 // InvalidRate is the fraction of nearby tickets having an invalid value, between 0 and 1.
+	Seed        int64   // Seed makes the generation reproducible.
+}
+
+// Generated holds synthetic notes along with their known answers.
+type Generated struct {
+	Notes    *Notes
+	Ordering []string // Ordering is the field at each ticket position.
+	Part1    int64    // Part1 is the sum of the invalid values.
+	Part2    int64    // Part2 is the product of the departure fields of your ticket.
+}
+
+// generatedBandWidth is the number of values of each band (see GenerateNotes).
+const generatedBandWidth = 50
+
+// generatedDepartureFields is the number of fields whose name starts with "departure", as in the puzzle.
+const generatedDepartureFields = 6
+
+// GenerateNotes produces synthetic notes whose field ordering is known and can be inferred by elimination.
+//
+// The values are split into consecutive bands, one per field, separated by gaps. The k-th field to be
+// eliminated only takes values from band k, and its rule covers the bands 0 to k. So the values at its
+// position match its rule and the rules of all the fields eliminated after it, but none of the rules of
+// the fields eliminated before it. Invalid values are taken above the last band, where no rule matches.
+func GenerateNotes(opts GenerateOptions) (*Generated, error) {
+	if opts.Fields < 1 {
+		return nil, fmt.Errorf("the number of fields must be at least 1, not %d", opts.Fields)
+	}
+
+	if opts.Tickets < 0 {
+		return nil, fmt.Errorf("the number of tickets can't be negative, not %d", opts.Tickets)
+	}
+
+	if opts.InvalidRate < 0 || opts.InvalidRate > 1 {
+		return nil, fmt.Errorf("the invalid rate must be between 0 and 1, not %g", opts.InvalidRate)
+	}
+
+	rnd := rand.New(rand.NewSource(opts.Seed))
+
+	// Lay out the bands. Each band starts after a random gap.
+	bandStarts := make([]int64, opts.Fields)
+	next := int64(1 + rnd.Intn(generatedBandWidth))
+	for k := range bandStarts {
+		bandStarts[k] = next
+		next += generatedBandWidth + 1 + int64(rnd.Intn(generatedBandWidth))
+	}
+	bandEnd := func(k int) int64 {
+		return bandStarts[k] + generatedBandWidth - 1
+	}
+
+	// The field eliminated k-th gets band k and sits at position positions[k].
+	positions := rnd.Perm(opts.Fields)
+
+	names := make([]string, opts.Fields)
+	for idx, nameIdx := range rnd.Perm(opts.Fields) {
+		if nameIdx < generatedDepartureFields {
+			names[idx] = fmt.Sprintf("departure %d", nameIdx+1)
+		} else {
+			names[idx] = fmt.Sprintf("field %d", nameIdx+1)
+		}
+	}
+
+	// The rule of the k-th field covers the bands 0 to k, with a hole in one of the gaps when possible.
+	configs := make([]Configuration, opts.Fields)
+	for k := range configs {
+		ranges := []ValidRange{{Min: bandStarts[0], Max: bandEnd(k)}}
+
+		if k > 0 {
+			hole := rnd.Intn(k)
+			ranges = []ValidRange{
+				{Min: bandStarts[0], Max: bandEnd(hole)},
+				{Min: bandStarts[hole+1], Max: bandEnd(k)},
+			}
+		}
+
+		configs[k] = Configuration{Field: names[k], Ranges: ranges}
+	}
+
+	ordering := make([]string, opts.Fields)
+	for k, position := range positions {
+		ordering[position] = names[k]
+	}
+
+	// validTicket draws a value of its band for each field.
+	validTicket := func() Ticket {
+		values := make([]int64, opts.Fields)
+		for k, position := range positions {
+			values[position] = bandStarts[k] + int64(rnd.Intn(generatedBandWidth))
+		}
+
+		return Ticket{Values: values}
+	}
+
+	generated := &Generated{Ordering: ordering, Part2: 1}
+	notes := &Notes{MyTicket: validTicket(), NearbyTickets: make([]Ticket, opts.Tickets)}
+
+	invalidCount := int(opts.InvalidRate*float64(opts.Tickets) + 0.5)
+	invalidTickets := make(map[int]bool, invalidCount)
+	for _, idx := range rnd.Perm(opts.Tickets)[:invalidCount] {
+		invalidTickets[idx] = true
+	}
+
+	for idx := range notes.NearbyTickets {
+		ticket := validTicket()
+
+		if invalidTickets[idx] {
+			value := bandEnd(opts.Fields-1) + 1 + int64(rnd.Intn(generatedBandWidth))
+			ticket.Values[rnd.Intn(opts.Fields)] = value
+			generated.Part1 += value
+		}
+
+		notes.NearbyTickets[idx] = ticket
+	}
+
+	for position, field := range ordering {
+		if strings.HasPrefix(field, DeparturePrefix) {
+			generated.Part2 *= notes.MyTicket.Values[position]
+		}
+	}
+
+	// Shuffle the rules so that their order tells nothing about the ordering.
+	rnd.Shuffle(len(configs), func(i, j int) {
+		configs[i], configs[j] = configs[j], configs[i]
+	})
+	notes.Configs = configs
+
+	generated.Notes = notes
+	return generated, nil
+}
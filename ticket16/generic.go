@@ -0,0 +1,54 @@
+package ticket16
+
+import (
+	"context"
+	"errors"
+)
+
+// The solver works with the int64 values produced by the parsers. ValidateOf and InferOrderingOf run the
+// same validation and inference on the values of any other Number type, e.g. the tickets of a float64
+// or uint64 source converted with ConvertTicket and ConvertConfiguration.
+
+// ValidationOf tells which tickets of any value type are valid, like Validation.
+type ValidationOf[T Number] struct {
+	Valid   []TicketOf[T] // Valid lists the tickets whose values all match a rule, in the order of the tickets.
+	Invalid []int         // Invalid lists the indexes of the other tickets, in the order of the tickets.
+
+	// ErrorRate is the sum of the values matching no rule, which is the answer to part 1.
+	ErrorRate T
+}
+
+// ValidateOf checks the tickets against the configurations and the extra rules, like Solver.Validate.
+func ValidateOf[T Number](configs []ConfigurationOf[T], tickets []TicketOf[T], rules ...RuleOf[T]) ValidationOf[T] {
+	index := newValidIndex(compileRules(configs, rules))
+
+	validation := ValidationOf[T]{}
+	for idx, ticket := range tickets {
+		if index.validTicket(ticket) {
+			validation.Valid = append(validation.Valid, ticket)
+			continue
+		}
+
+		validation.Invalid = append(validation.Invalid, idx)
+		for _, value := range index.invalidValues(ticket) {
+			validation.ErrorRate += value
+		}
+	}
+
+	return validation
+}
+
+// InferOrderingOf works out the field held by each position of the tickets, from the configurations and
+// the extra rules, like Solver.InferOrderingContext. All the tickets must be valid. The solver picks the
+// algorithm and the parallelism, its own rules only apply to int64 values and are left out.
+func InferOrderingOf[T Number](ctx context.Context, s *Solver, configs []ConfigurationOf[T], tickets []TicketOf[T], rules ...RuleOf[T]) ([]string, error) {
+	if len(tickets) == 0 {
+		return nil, errors.New("no valid ticket to infer the ordering from")
+	}
+
+	compiled := compileRules(configs, rules)
+	candidates := candidateMatrix(len(tickets[0].Values), tickets, compiled, s.workers())
+
+	ordering, _, _, err := s.inferOrdering(ctx, candidates, ruleNames(compiled))
+	return ordering, err
+}
@@ -0,0 +1,54 @@
+package ticket16
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// checkGeneric solves the example with the values converted to T, which must give the answers of int64.
+func checkGeneric[T Number](t *testing.T, notes *Notes, want Result) {
+	t.Helper()
+
+	configs := make([]ConfigurationOf[T], len(notes.Configs))
+	for idx, config := range notes.Configs {
+		configs[idx] = ConvertConfiguration[T](config)
+	}
+	tickets := make([]TicketOf[T], len(notes.NearbyTickets))
+	for idx, ticket := range notes.NearbyTickets {
+		tickets[idx] = ConvertTicket[T](ticket)
+	}
+
+	validation := ValidateOf(configs, tickets)
+	if validation.ErrorRate != T(want.Part1) {
+		t.Errorf("error rate = %v, want %d", validation.ErrorRate, want.Part1)
+	}
+	if len(validation.Valid) != want.ValidTickets || len(validation.Invalid) != want.InvalidTickets {
+		t.Errorf("%d valid and %d invalid tickets, want %d and %d", len(validation.Valid), len(validation.Invalid), want.ValidTickets, want.InvalidTickets)
+	}
+
+	ordering, err := InferOrderingOf(context.Background(), NewSolver(), configs, append(validation.Valid, ConvertTicket[T](notes.MyTicket)))
+	if err != nil {
+		t.Fatalf("InferOrderingOf failed: %s", err)
+	}
+	if !reflect.DeepEqual(ordering, want.Ordering) {
+		t.Errorf("ordering = %v, want %v", ordering, want.Ordering)
+	}
+}
+
+func TestGenericValues(t *testing.T) {
+	notes, err := ParseNotes(strings.NewReader(ExampleInput), ParseOptions{})
+	if err != nil {
+		t.Fatalf("ParseNotes failed: %s", err)
+	}
+
+	want, err := SolveNotes(notes)
+	if err != nil {
+		t.Fatalf("SolveNotes failed: %s", err)
+	}
+
+	t.Run("int32", func(t *testing.T) { checkGeneric[int32](t, notes, want) })
+	t.Run("uint64", func(t *testing.T) { checkGeneric[uint64](t, notes, want) })
+	t.Run("float64", func(t *testing.T) { checkGeneric[float64](t, notes, want) })
+}
@@ -0,0 +1,164 @@
+package ticket16
+
+import (
+	"errors"
+	"fmt"
+)
+
+// incrementalState keeps what the tickets added one at a time taught the solver so far.
+type incrementalState struct {
+	configs    []RuleOf[int64]
+	index      validIndex[int64]
+	errorRate  int64
+	tickets    int      // tickets counts the valid tickets added.
+	candidates [][]bool // candidates tells, by position, which configurations match all the valid tickets.
+
+	// ordering caches the result of CurrentOrdering until the next valid ticket is added. It is never
+	// modified once computed, so it can be shared by the readers.
+	ordering    []string
+	orderingErr error
+	cached      bool
+}
+
+// SetRules starts an incremental solve with the given rules, forgetting the tickets added before.
+// The tickets are then added one at a time with AddTicket, and the partial answers are available at
+// any time from ErrorRate and CurrentOrdering, e.g. while streaming the notes with StreamNotes.
+func (s *Solver) SetRules(configs []Configuration) {
+	compiled := compileRules(configs, s.rules)
+	state := &incrementalState{configs: compiled, index: newValidIndex(compiled)}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.incremental = state
+}
+
+// AddTicket adds a ticket to the incremental solve started by SetRules. The values of an invalid ticket
+// are added to the error rate, while a valid ticket narrows the fields each position may hold. Your own
+// ticket, which is assumed to be valid, should be added too.
+func (s *Solver) AddTicket(ticket Ticket) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state := s.incremental
+	if state == nil {
+		return errors.New("no rules set for the incremental solve")
+	}
+
+	invalids := state.index.invalidValues(ticket)
+	if len(invalids) > 0 {
+		for _, value := range invalids {
+			state.errorRate += value
+		}
+
+		return nil
+	}
+
+	// The first valid ticket tells how many positions there are, all the configurations being candidates.
+	if state.candidates == nil {
+		state.candidates = allCandidates(len(ticket.Values), len(state.configs))
+	} else if len(ticket.Values) != len(state.candidates) {
+		return fmt.Errorf("ticket has %d values instead of %d", len(ticket.Values), len(state.candidates))
+	}
+
+	narrowTicket(state.candidates, ticket, nil, state.configs)
+	state.tickets++
+	state.cached = false
+
+	return nil
+}
+
+// ErrorRate returns the sum of the invalid values of the tickets added so far.
+func (s *Solver) ErrorRate() int64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.incremental == nil {
+		return 0
+	}
+
+	return s.incremental.errorRate
+}
+
+// CurrentOrdering returns the ordering known from the tickets added so far. The positions that the
+// tickets don't tell apart yet are left empty. It returns an UnsolvableError as soon as a position
+// matches none of the fields left.
+func (s *Solver) CurrentOrdering() ([]string, error) {
+	ordering, err := s.currentOrdering()
+	if err != nil {
+		return nil, err
+	}
+
+	// The cached ordering is shared, the caller gets its own copy.
+	return append([]string(nil), ordering...), nil
+}
+
+// currentOrdering returns the cached ordering, computing it first when a ticket was added since.
+func (s *Solver) currentOrdering() ([]string, error) {
+	s.mu.RLock()
+	state := s.incremental
+	if state != nil && state.cached {
+		defer s.mu.RUnlock()
+		return state.ordering, state.orderingErr
+	}
+	s.mu.RUnlock()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state = s.incremental
+	if state == nil || state.candidates == nil {
+		return nil, errors.New("no valid ticket added to the incremental solve")
+	}
+
+	// Another goroutine may have computed it while we were waiting for the lock.
+	if !state.cached {
+		state.ordering, state.orderingErr = state.order()
+		state.cached = true
+	}
+
+	return state.ordering, state.orderingErr
+}
+
+// Decode labels the values of the ticket with the fields of the current ordering, see TicketOf.Decode.
+// The values of the positions that are not known yet are left out.
+func (s *Solver) Decode(ticket Ticket) (map[string]int64, error) {
+	ordering, err := s.currentOrdering()
+	if err != nil {
+		return nil, err
+	}
+
+	decoded := ticket.Decode(ordering)
+	delete(decoded, "")
+
+	return decoded, nil
+}
+
+// order runs the elimination on the candidates known so far.
+func (state *incrementalState) order() ([]string, error) {
+	fields := make([]string, len(state.configs))
+	for idx, config := range state.configs {
+		fields[idx] = config.Name()
+	}
+
+	// The elimination works on a copy, so that later tickets keep narrowing the candidates of all the positions.
+	elimination := newElimination(state.candidates, len(fields))
+	for !elimination.done() {
+		if err := elimination.unsolvable(fields); err != nil {
+			return nil, err
+		}
+
+		if !elimination.step() {
+			break
+		}
+	}
+
+	ordering := make([]string, len(state.candidates))
+	for fieldPos, idx := range elimination.fieldOf {
+		if idx >= 0 {
+			ordering[fieldPos] = fields[idx]
+		}
+	}
+
+	return ordering, nil
+}
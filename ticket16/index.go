@@ -0,0 +1,180 @@
+package ticket16
+
+// validationBlock is the number of values validTicket checks at once, without stopping at the first
+// invalid one, so that the checks of a block don't branch on their outcome.
+const validationBlock = 8
+
+// validIndex tells whether a value matches any of the rules without trying them one by one: the values
+// allowed by the configurations are merged into a single range set, checked with a single bit test when
+// it spans few values, see DenseDomainLimit, or else with a branch-free binary search of its bounds. Only
+// the custom rules, whose values are unknown, are still tried one by one.
+type validIndex[T Number] struct {
+	allowed RangeSetOf[T]
+	dense   *denseTable[T] // dense is the lookup table of allowed, when it has one.
+	edges   []T            // edges holds the bounds of allowed when it has no lookup table, see rangeEdges.
+	custom  []RuleOf[T]
+}
+
+// newValidIndex indexes the rules, as compiled by compileRules.
+func newValidIndex[T Number](rules []RuleOf[T]) validIndex[T] {
+	var allowed []ValidRangeOf[T]
+	var custom []RuleOf[T]
+
+	for _, rule := range rules {
+		config, ok := rule.(compiledConfig[T])
+
+		// The exclusions can only be cut out of integer ranges, the other configurations are tried as they are.
+		if !ok || (!isInteger[T]() && len(config.exclusions.ranges) > 0) {
+			custom = append(custom, rule)
+			continue
+		}
+
+		allowed = append(allowed, config.ranges.subtract(config.exclusions).ranges...)
+	}
+
+	index := validIndex[T]{allowed: NewRangeSet(allowed...), custom: custom}
+	if index.dense = newDenseTable(index.allowed); index.dense == nil {
+		index.edges = rangeEdges(index.allowed)
+	}
+
+	return index
+}
+
+// rangeEdges returns the bounds of the integer ranges of the set, each range adding its minimum and the
+// value following its maximum, so that a value is in the set when an odd number of them are not greater
+// than it. It returns nil when the set doesn't hold integers, is empty or unbounded, or ends with the
+// greatest value of T.
+func rangeEdges[T Number](set RangeSetOf[T]) []T {
+	if !isInteger[T]() || len(set.ranges) == 0 {
+		return nil
+	}
+
+	edges := make([]T, 0, 2*len(set.ranges))
+	for _, rng := range set.ranges {
+		if rng.NoMin || rng.NoMax || rng.Max+1 < rng.Max {
+			return nil
+		}
+
+		edges = append(edges, rng.Min, rng.Max+1)
+	}
+
+	return edges
+}
+
+// edgeMiss returns 1 when the value is outside the ranges of the edges, see rangeEdges, and 0 otherwise.
+// The search halves the edges without branching on the comparisons, which the CPU can't predict.
+func edgeMiss[T Number](edges []T, value T) uint64 {
+	base, n := 0, len(edges)
+	for n > 1 {
+		half := n / 2
+		if edges[base+half] <= value {
+			base += half
+		}
+		n -= half
+	}
+
+	// edges[base] is the last edge not greater than the value, a minimum when base is even.
+	if edges[base] > value {
+		return 1
+	}
+
+	return uint64(base & 1)
+}
+
+// miss returns 1 when the value is allowed by none of the configurations, and 0 otherwise. The custom
+// rules are left to valid.
+func (i validIndex[T]) miss(value T) uint64 {
+	switch {
+	case i.dense != nil:
+		return i.dense.miss(value)
+	case i.edges != nil:
+		return edgeMiss(i.edges, value)
+	case i.allowed.Contains(value):
+		return 0
+	default:
+		return 1
+	}
+}
+
+// valid tells whether the value matches any of the rules.
+func (i validIndex[T]) valid(value T) bool {
+	if i.miss(value) == 0 {
+		return true
+	}
+
+	for _, rule := range i.custom {
+		if rule.Matches(value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// validTicket tells whether all the known values of the ticket match a rule. Without custom rules and
+// unknown values, the values are checked validationBlock at a time.
+func (i validIndex[T]) validTicket(ticket TicketOf[T]) bool {
+	if len(i.custom) == 0 && len(ticket.Unknown) == 0 {
+		return i.validValues(ticket.Values)
+	}
+
+	for fieldPos, value := range ticket.Values {
+		if ticket.Known(fieldPos) && !i.valid(value) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// validValues tells whether the configurations allow all the values, only stopping between the blocks.
+func (i validIndex[T]) validValues(values []T) bool {
+	for len(values) >= validationBlock {
+		var miss uint64
+		for _, value := range (*[validationBlock]T)(values) {
+			miss |= i.miss(value)
+		}
+		if miss != 0 {
+			return false
+		}
+
+		values = values[validationBlock:]
+	}
+
+	var miss uint64
+	for _, value := range values {
+		miss |= i.miss(value)
+	}
+
+	return miss == 0
+}
+
+// invalidValues returns the known values of the ticket matching none of the rules, like TicketOf.Validate.
+func (i validIndex[T]) invalidValues(ticket TicketOf[T]) []T {
+	var invalids []T
+	for fieldPos, value := range ticket.Values {
+		if ticket.Known(fieldPos) && !i.valid(value) {
+			invalids = append(invalids, value)
+		}
+	}
+
+	return invalids
+}
+
+// explainTicket is like explainValues, stopping at the first invalid value when collecting the first ones,
+// but only tries the rules one by one on the values the index rejects, the others matching a rule.
+func explainTicket(ticket Ticket, configs []RuleOf[int64], index validIndex[int64], collection InvalidCollection) []InvalidValue {
+	var invalids []InvalidValue
+	for fieldPos, value := range ticket.Values {
+		if !ticket.Known(fieldPos) || index.valid(value) {
+			continue
+		}
+
+		invalids = append(invalids, explainValue(fieldPos, value, configs))
+		if collection == CollectFirst {
+			break
+		}
+	}
+
+	return invalids
+}
@@ -0,0 +1,165 @@
+package ticket16
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// InvalidValue describes a value of a ticket that matches no rule.
+type InvalidValue struct {
+	Position int   // Position is the 0-based position of the value in the ticket.
+	Value    int64 // Value is the value itself.
+
+	// Closest lists the fields whose rules came closest to matching the value, in the order of the rules,
+	// and Distance tells how far the value is from the nearest value they allow.
+	Closest  []string
+	Distance int64
+}
+
+// String implements the fmt.Stringer interface, e.g. `value 25 at position 3, 2 away from "class"`.
+func (v InvalidValue) String() string {
+	if len(v.Closest) == 0 {
+		return fmt.Sprintf("value %d at position %d", v.Value, v.Position)
+	}
+
+	return fmt.Sprintf("value %d at position %d, %d away from %s", v.Value, v.Position, v.Distance, quoteFields(v.Closest))
+}
+
+// quoteFields quotes each of the fields and joins them with commas, e.g. `"row", "seat"`.
+func quoteFields(fields []string) string {
+	quoted := make([]string, len(fields))
+	for idx, field := range fields {
+		quoted[idx] = strconv.Quote(field)
+	}
+
+	return strings.Join(quoted, ", ")
+}
+
+// InvalidTicket describes a nearby ticket holding values that match no rule.
+type InvalidTicket struct {
+	Index  int            // Index is the 0-based index of the ticket among the nearby tickets.
+	Ticket Ticket         // Ticket is the ticket itself.
+	Values []InvalidValue // Values lists the values matching no rule, in the order of the ticket.
+}
+
+// ExplainTicket returns the values of the ticket that match none of the rules, with the rules that came
+// closest to matching each of them. It returns nil when the ticket is valid.
+func ExplainTicket(ticket Ticket, configs []Configuration) []InvalidValue {
+	return explainValues(ticket, compileRules(configs, nil))
+}
+
+// distancer is implemented by the rules able to tell how far a value is from matching.
+type distancer[T Number] interface {
+	distance(value T) T
+}
+
+// explainValues is like ExplainTicket, with configurations that are already compiled. The custom rules,
+// which can't tell how far a value is from matching them, are never listed among the closest.
+func explainValues(ticket Ticket, configs []RuleOf[int64]) []InvalidValue {
+	var invalids []InvalidValue
+
+	for fieldPos, value := range ticket.Values {
+		if !ticket.Known(fieldPos) {
+			continue
+		}
+
+		if invalid := explainValue(fieldPos, value, configs); invalid.Distance != 0 {
+			invalids = append(invalids, invalid)
+		}
+	}
+
+	return invalids
+}
+
+// explainValue tells how far the value at the position is from the closest rules. Its distance is zero
+// when a rule matches it.
+func explainValue(fieldPos int, value int64, configs []RuleOf[int64]) InvalidValue {
+	invalid := InvalidValue{Position: fieldPos, Value: value, Distance: -1}
+
+	for _, config := range configs {
+		if config.Matches(value) {
+			invalid.Distance = 0
+			break
+		}
+
+		rule, ok := config.(distancer[int64])
+		if !ok {
+			continue
+		}
+
+		distance := rule.distance(value)
+		if distance < 0 {
+			continue
+		}
+
+		if invalid.Distance < 0 || distance < invalid.Distance {
+			invalid.Distance = distance
+			invalid.Closest = []string{config.Name()}
+		} else if distance == invalid.Distance {
+			invalid.Closest = append(invalid.Closest, config.Name())
+		}
+	}
+
+	return invalid
+}
+
+// Distance returns how far the value is from the nearest value of the set: zero when the value is in the
+// set, and -1 when the set is empty.
+func (s RangeSetOf[T]) Distance(value T) T {
+	distance := T(0)
+	found := false
+
+	for _, rng := range s.ranges {
+		var d T
+		switch {
+		case rng.Contains(value):
+			return 0
+		case !rng.NoMin && value < rng.Min:
+			d = rng.Min - value
+		default:
+			d = value - rng.Max
+		}
+
+		if !found || d < distance {
+			distance, found = d, true
+		}
+	}
+
+	if !found {
+		return T(0) - 1
+	}
+
+	return distance
+}
+
+// distance returns how far the value is from the nearest value matching the configuration: zero when the
+// value matches, and -1 when the configuration matches nothing. An excluded value is as far as the nearest
+// edge of the exclusion, which is a good enough estimate for the reports.
+func (c compiledConfig[T]) distance(value T) T {
+	distance := c.ranges.Distance(value)
+	if distance != 0 || !c.exclusions.Contains(value) {
+		return distance
+	}
+
+	for _, rng := range c.exclusions.ranges {
+		if !rng.Contains(value) {
+			continue
+		}
+
+		switch {
+		case rng.NoMin && rng.NoMax:
+			return T(0) - 1
+		case rng.NoMin:
+			return rng.Max - value + 1
+		case rng.NoMax:
+			return value - rng.Min + 1
+		case value-rng.Min < rng.Max-value:
+			return value - rng.Min + 1
+		default:
+			return rng.Max - value + 1
+		}
+	}
+
+	return distance
+}
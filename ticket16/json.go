@@ -0,0 +1,458 @@
+package ticket16
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"time"
+)
+
+// DecodeNotesJSON reads the notes from a JSON document of the form:
+//
+//	{
+//	  "rules": [{"field": "class", "ranges": [{"min": 1, "max": 3}, {"min": 5, "max": 7}], "exclusions": [{"min": 2, "max": 2}]}],
+//	  "your_ticket": [7, 1, 14],
+//	  "nearby_tickets": [[7, 3, 47], [40, 4, 50]]
+//	}
+//
+// Each range holds at least one bound, and the exclusions are optional. Unknown keys are rejected so that
+// typos don't go unnoticed.
+func DecodeNotesJSON(r io.Reader) (*Notes, error) {
+	return decodeNotesJSON(r, ParseOptions{})
+}
+
+// decodeNotesJSON is DecodeNotesJSON, with the options of DecodeNotes.
+func decodeNotesJSON(r io.Reader, opts ParseOptions) (*Notes, error) {
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+
+	var doc notesDocument
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("invalid JSON notes: %w", err)
+	}
+
+	notes, err := doc.toNotes(opts)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON notes: %w", err)
+	}
+
+	return notes, nil
+}
+
+// The core types are encoded in JSON with the schema of the notes documents read by DecodeNotesJSON, so
+// that they can be exchanged with other tools:
+//
+//	range:         {"min": 1, "max": 3}, a missing bound meaning the range is open on that side
+//	configuration: {"field": "class", "ranges": [...], "exclusions": [...]}, the exclusions being optional
+//	ticket:        [7, 1, 14]
+
+// rangeJSON is the JSON representation of a ValidRangeOf.
+type rangeJSON[T Number] struct {
+	Min *T `json:"min,omitempty"`
+	Max *T `json:"max,omitempty"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (r ValidRangeOf[T]) MarshalJSON() ([]byte, error) {
+	doc := rangeJSON[T]{}
+
+	if !r.NoMin {
+		min := r.Min
+		doc.Min = &min
+	}
+
+	if !r.NoMax {
+		max := r.Max
+		doc.Max = &max
+	}
+
+	return json.Marshal(doc)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (r *ValidRangeOf[T]) UnmarshalJSON(data []byte) error {
+	var doc rangeJSON[T]
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+
+	*r = ValidRangeOf[T]{NoMin: doc.Min == nil, NoMax: doc.Max == nil}
+
+	if doc.Min != nil {
+		r.Min = *doc.Min
+	}
+
+	if doc.Max != nil {
+		r.Max = *doc.Max
+	}
+
+	return nil
+}
+
+// configurationJSON is the JSON representation of a ConfigurationOf.
+type configurationJSON[T Number] struct {
+	Field      string            `json:"field"`
+	Ranges     []ValidRangeOf[T] `json:"ranges"`
+	Exclusions []ValidRangeOf[T] `json:"exclusions,omitempty"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (c ConfigurationOf[T]) MarshalJSON() ([]byte, error) {
+	ranges := c.Ranges
+	if ranges == nil {
+		ranges = []ValidRangeOf[T]{}
+	}
+
+	return json.Marshal(configurationJSON[T]{Field: c.Field, Ranges: ranges, Exclusions: c.Exclusions})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (c *ConfigurationOf[T]) UnmarshalJSON(data []byte) error {
+	var doc configurationJSON[T]
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+
+	*c = ConfigurationOf[T]{Field: doc.Field, Ranges: doc.Ranges, Exclusions: doc.Exclusions}
+	return nil
+}
+
+// MarshalJSON implements the json.Marshaler interface. The unknown values are written as null.
+func (t TicketOf[T]) MarshalJSON() ([]byte, error) {
+	values := make([]*T, len(t.Values))
+	for idx := range t.Values {
+		if t.Known(idx) {
+			values[idx] = &t.Values[idx]
+		}
+	}
+
+	return json.Marshal(values)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. The null values are unknown.
+func (t *TicketOf[T]) UnmarshalJSON(data []byte) error {
+	var values []*T
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+
+	*t = ticketOf(values)
+	return nil
+}
+
+// ticketOf builds a ticket from values that may be nil when unknown.
+func ticketOf[T Number](values []*T) TicketOf[T] {
+	ticket := TicketOf[T]{Values: make([]T, len(values))}
+	for idx, value := range values {
+		if value != nil {
+			ticket.Values[idx] = *value
+			continue
+		}
+
+		if ticket.Unknown == nil {
+			ticket.Unknown = make([]bool, len(values))
+		}
+		ticket.Unknown[idx] = true
+	}
+
+	return ticket
+}
+
+// resultJSON is the JSON representation of a Result. The timings are in nanoseconds.
+type resultJSON struct {
+	Part1          int64          `json:"part1"`
+	Part2          int64          `json:"part2"`
+	Part2Values    []int64        `json:"part2_values,omitempty"`
+	Part2Unknown   []string       `json:"part2_unknown,omitempty"`
+	Part1Overflow  bool           `json:"part1_overflow,omitempty"`
+	Part2Overflow  bool           `json:"part2_overflow,omitempty"`
+	Aggregation    Aggregation    `json:"aggregation,omitempty"`
+	Part1Big       *big.Int       `json:"part1_big,omitempty"`
+	Part2Big       *big.Int       `json:"part2_big,omitempty"`
+	Ordering       []string       `json:"ordering"`
+	Positions      []positionJSON `json:"positions"`
+	ValidTickets   int            `json:"valid_tickets"`
+	InvalidTickets int            `json:"invalid_tickets"`
+	Timings        timingsJSON    `json:"timings"`
+
+	ToleratedTickets int             `json:"tolerated_tickets,omitempty"`
+	RaggedTickets    int             `json:"ragged_tickets,omitempty"`
+	Groups           []groupJSON     `json:"groups,omitempty"`
+	Violations       []violationJSON `json:"violations,omitempty"`
+
+	MyTicketInvalid []invalidValueJSON  `json:"my_ticket_invalid,omitempty"`
+	InvalidDetails  []invalidTicketJSON `json:"invalid_details,omitempty"`
+	Metrics         *metricsJSON        `json:"metrics,omitempty"`
+	Duplicates      *duplicatesJSON     `json:"duplicates,omitempty"`
+	Rounds          int                 `json:"rounds,omitempty"`
+}
+
+// duplicatesJSON is the JSON representation of DuplicateStats.
+type duplicatesJSON struct {
+	Unique     int `json:"unique"`
+	Duplicates int `json:"duplicates"`
+	MaxCount   int `json:"max_count"`
+}
+
+// metricsJSON is the JSON representation of ErrorMetrics.
+type metricsJSON struct {
+	Discarded int                  `json:"discarded"`
+	Rules     []ruleMetricJSON     `json:"rules"`
+	Positions []positionMetricJSON `json:"positions"`
+}
+
+// ruleMetricJSON is the JSON representation of a RuleMetric.
+type ruleMetricJSON struct {
+	Field    string `json:"field"`
+	Accepted int    `json:"accepted"`
+}
+
+// positionMetricJSON is the JSON representation of a PositionMetric.
+type positionMetricJSON struct {
+	Position int   `json:"position"`
+	Invalid  int   `json:"invalid"`
+	Sum      int64 `json:"sum"`
+}
+
+// positionJSON is the JSON representation of a PositionReport.
+type positionJSON struct {
+	Position   int      `json:"position"`
+	Field      string   `json:"field"`
+	Candidates []string `json:"candidates"`
+	Forced     bool     `json:"forced"`
+}
+
+// groupJSON is the JSON representation of a Group.
+type groupJSON struct {
+	Prefix string   `json:"prefix"`
+	Fields []string `json:"fields"`
+	Values []int64  `json:"values"`
+	Value  int64    `json:"value"`
+
+	BigValue *big.Int `json:"big_value,omitempty"`
+	Overflow bool     `json:"overflow,omitempty"`
+	Unknown  []string `json:"unknown,omitempty"`
+}
+
+// violationJSON is the JSON representation of a Violation.
+type violationJSON struct {
+	Ticket   int    `json:"ticket"`
+	Position int    `json:"position"`
+	Field    string `json:"field"`
+	Value    int64  `json:"value"`
+}
+
+// invalidValueJSON is the JSON representation of an InvalidValue.
+type invalidValueJSON struct {
+	Position int      `json:"position"`
+	Value    int64    `json:"value"`
+	Closest  []string `json:"closest,omitempty"`
+	Distance int64    `json:"distance"`
+}
+
+// invalidTicketJSON is the JSON representation of an InvalidTicket.
+type invalidTicketJSON struct {
+	Index  int                `json:"index"`
+	Ticket Ticket             `json:"ticket"`
+	Values []invalidValueJSON `json:"values"`
+}
+
+// timingsJSON is the JSON representation of Timings.
+type timingsJSON struct {
+	Parse    int64 `json:"parse_ns"`
+	Validate int64 `json:"validate_ns"`
+	Order    int64 `json:"order_ns"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (r Result) MarshalJSON() ([]byte, error) {
+	ordering := r.Ordering
+	if ordering == nil {
+		ordering = []string{}
+	}
+
+	positions := make([]positionJSON, len(r.Positions))
+	for idx, position := range r.Positions {
+		positions[idx] = positionJSON(position)
+	}
+
+	var groups []groupJSON
+	for _, group := range r.Groups {
+		groups = append(groups, groupJSON(group))
+	}
+
+	var violations []violationJSON
+	for _, violation := range r.Violations {
+		violations = append(violations, violationJSON(violation))
+	}
+
+	var myTicketInvalid []invalidValueJSON
+	for _, invalid := range r.MyTicketInvalid {
+		myTicketInvalid = append(myTicketInvalid, invalidValueJSON(invalid))
+	}
+
+	var invalidDetails []invalidTicketJSON
+	for _, ticket := range r.InvalidDetails {
+		values := make([]invalidValueJSON, len(ticket.Values))
+		for idx, invalid := range ticket.Values {
+			values[idx] = invalidValueJSON(invalid)
+		}
+		invalidDetails = append(invalidDetails, invalidTicketJSON{Index: ticket.Index, Ticket: ticket.Ticket, Values: values})
+	}
+
+	var metrics *metricsJSON
+	if r.Metrics != nil {
+		metrics = &metricsJSON{
+			Discarded: r.Metrics.Discarded,
+			Rules:     []ruleMetricJSON{},
+			Positions: []positionMetricJSON{},
+		}
+		for _, rule := range r.Metrics.Rules {
+			metrics.Rules = append(metrics.Rules, ruleMetricJSON(rule))
+		}
+		for _, position := range r.Metrics.Positions {
+			metrics.Positions = append(metrics.Positions, positionMetricJSON(position))
+		}
+	}
+
+	return json.Marshal(resultJSON{
+		Part1:          r.Part1,
+		Part2:          r.Part2,
+		Part2Values:    r.Part2Values,
+		Part2Unknown:   r.Part2Unknown,
+		Part1Overflow:  r.Part1Overflow,
+		Part2Overflow:  r.Part2Overflow,
+		Aggregation:    r.Aggregation,
+		Part1Big:       r.Part1Big,
+		Part2Big:       r.Part2Big,
+		Ordering:       ordering,
+		Positions:      positions,
+		ValidTickets:   r.ValidTickets,
+		InvalidTickets: r.InvalidTickets,
+		Timings: timingsJSON{
+			Parse:    int64(r.Timings.Parse),
+			Validate: int64(r.Timings.Validate),
+			Order:    int64(r.Timings.Order),
+		},
+		ToleratedTickets: r.ToleratedTickets,
+		RaggedTickets:    r.RaggedTickets,
+		Groups:           groups,
+		Violations:       violations,
+		MyTicketInvalid:  myTicketInvalid,
+		InvalidDetails:   invalidDetails,
+		Metrics:          metrics,
+		Duplicates:       (*duplicatesJSON)(r.Duplicates),
+		Rounds:           r.Rounds,
+	})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (r *Result) UnmarshalJSON(data []byte) error {
+	var doc resultJSON
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+
+	var positions []PositionReport
+	for _, position := range doc.Positions {
+		positions = append(positions, PositionReport(position))
+	}
+
+	var groups []Group
+	for _, group := range doc.Groups {
+		groups = append(groups, Group(group))
+	}
+
+	var violations []Violation
+	for _, violation := range doc.Violations {
+		violations = append(violations, Violation(violation))
+	}
+
+	var myTicketInvalid []InvalidValue
+	for _, invalid := range doc.MyTicketInvalid {
+		myTicketInvalid = append(myTicketInvalid, InvalidValue(invalid))
+	}
+
+	var invalidDetails []InvalidTicket
+	for _, ticket := range doc.InvalidDetails {
+		values := make([]InvalidValue, len(ticket.Values))
+		for idx, invalid := range ticket.Values {
+			values[idx] = InvalidValue(invalid)
+		}
+		invalidDetails = append(invalidDetails, InvalidTicket{Index: ticket.Index, Ticket: ticket.Ticket, Values: values})
+	}
+
+	var metrics *ErrorMetrics
+	if doc.Metrics != nil {
+		metrics = &ErrorMetrics{Discarded: doc.Metrics.Discarded}
+		for _, rule := range doc.Metrics.Rules {
+			metrics.Rules = append(metrics.Rules, RuleMetric(rule))
+		}
+		for _, position := range doc.Metrics.Positions {
+			metrics.Positions = append(metrics.Positions, PositionMetric(position))
+		}
+	}
+
+	*r = Result{
+		Part1:          doc.Part1,
+		Part2:          doc.Part2,
+		Part2Values:    doc.Part2Values,
+		Part2Unknown:   doc.Part2Unknown,
+		Part1Overflow:  doc.Part1Overflow,
+		Part2Overflow:  doc.Part2Overflow,
+		Aggregation:    doc.Aggregation,
+		Part1Big:       doc.Part1Big,
+		Part2Big:       doc.Part2Big,
+		Ordering:       doc.Ordering,
+		Positions:      positions,
+		ValidTickets:   doc.ValidTickets,
+		InvalidTickets: doc.InvalidTickets,
+		Timings: Timings{
+			Parse:    time.Duration(doc.Timings.Parse),
+			Validate: time.Duration(doc.Timings.Validate),
+			Order:    time.Duration(doc.Timings.Order),
+		},
+		ToleratedTickets: doc.ToleratedTickets,
+		RaggedTickets:    doc.RaggedTickets,
+		Groups:           groups,
+		Violations:       violations,
+		MyTicketInvalid:  myTicketInvalid,
+		InvalidDetails:   invalidDetails,
+		Metrics:          metrics,
+		Duplicates:       (*DuplicateStats)(doc.Duplicates),
+		Rounds:           doc.Rounds,
+	}
+
+	return nil
+}
+
+// checkpointJSON is the JSON representation of a Checkpoint.
+type checkpointJSON struct {
+	Rules             string   `json:"rules"`
+	Tickets           int      `json:"tickets"`
+	Digest            []byte   `json:"digest"`
+	Candidates        [][]int  `json:"candidates"`
+	ErrorRate         int64    `json:"error_rate"`
+	ErrorRateOverflow bool     `json:"error_rate_overflow,omitempty"`
+	ErrorRateBig      *big.Int `json:"error_rate_big,omitempty"`
+	Valid             int      `json:"valid_tickets"`
+	Invalid           int      `json:"invalid_tickets"`
+	Tolerated         int      `json:"tolerated_tickets"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (c Checkpoint) MarshalJSON() ([]byte, error) {
+	return json.Marshal(checkpointJSON(c))
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (c *Checkpoint) UnmarshalJSON(data []byte) error {
+	var doc checkpointJSON
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+
+	*c = Checkpoint(doc)
+	return nil
+}
@@ -0,0 +1,201 @@
+package ticket16
+
+// unmatched marks a position or a field without a partner in the matching.
+const unmatched = -1
+
+// matching computes a maximum bipartite matching between the positions and the fields left by the
+// elimination with the Hopcroft–Karp algorithm: it repeatedly finds a maximal set of shortest
+// augmenting paths with a breadth-first search, then follows them with depth-first searches.
+type matching struct {
+	e          *elimination
+	fieldOf    []int // fieldOf holds the field matched with each position, unmatched when none.
+	positionOf []int // positionOf holds the position matched with each field, unmatched when none.
+	layer      []int // layer holds the distance of each free position from the start of the paths.
+}
+
+// match assigns the positions left by the elimination according to a maximum matching. When some of the
+// positions left can't all be given a different field, it assigns nothing and returns these positions.
+func (e *elimination) match() []int {
+	m := &matching{
+		e:          e,
+		fieldOf:    make([]int, len(e.candidates)),
+		positionOf: make([]int, len(e.positionOf)),
+		layer:      make([]int, len(e.candidates)),
+	}
+
+	for fieldPos := range m.fieldOf {
+		m.fieldOf[fieldPos] = unmatched
+	}
+	for idx := range m.positionOf {
+		m.positionOf[idx] = unmatched
+	}
+
+	matched := 0
+	for m.search() {
+		for fieldPos := range m.fieldOf {
+			if e.fieldOf[fieldPos] < 0 && m.fieldOf[fieldPos] == unmatched && m.augment(fieldPos) {
+				matched++
+			}
+		}
+	}
+
+	if matched < e.left {
+		var positions []int
+		for fieldPos, idx := range m.fieldOf {
+			if e.fieldOf[fieldPos] < 0 && idx == unmatched {
+				positions = append(positions, fieldPos)
+			}
+		}
+
+		return positions
+	}
+
+	for fieldPos, idx := range m.fieldOf {
+		if e.fieldOf[fieldPos] < 0 {
+			e.assign(fieldPos, idx)
+		}
+	}
+
+	return nil
+}
+
+// clone copies the state of the elimination, so that assignments can be tried on the copy.
+func (e *elimination) clone() *elimination {
+	state := &elimination{
+		candidates: make([]bitset, len(e.candidates)),
+		holders:    make([]bitset, len(e.holders)),
+		free:       append(bitset(nil), e.free...),
+		fieldOf:    append([]int(nil), e.fieldOf...),
+		positionOf: append([]int(nil), e.positionOf...),
+		left:       e.left,
+	}
+
+	for fieldPos, row := range e.candidates {
+		state.candidates[fieldPos] = append(bitset(nil), row...)
+	}
+	for idx, holders := range e.holders {
+		state.holders[idx] = append(bitset(nil), holders...)
+	}
+
+	return state
+}
+
+// feasible tells whether the positions left can all be given a different field once the field is assigned
+// to the position.
+func (e *elimination) feasible(fieldPos int, idx int) bool {
+	trial := e.clone()
+	trial.assign(fieldPos, idx)
+
+	return trial.match() == nil
+}
+
+// pick assigns the positions left following the tie-break rule: from the first position to the last, each
+// takes the first of its candidates, in the order of the rules, that still lets the positions left all be
+// given a different field. It tells for each position whether its field was forced: the positions already
+// assigned were, and so is a position left with a single candidate doing so before any pick. When the
+// positions left can't all be given a field, it assigns nothing and returns these positions instead.
+func (e *elimination) pick() ([]bool, []int) {
+	if positions := e.clone().match(); positions != nil {
+		return nil, positions
+	}
+
+	forced := e.forced()
+
+	for fieldPos, row := range e.candidates {
+		if e.fieldOf[fieldPos] >= 0 {
+			continue
+		}
+
+		for idx := row.next(0); idx >= 0; idx = row.next(idx + 1) {
+			if e.feasible(fieldPos, idx) {
+				e.assign(fieldPos, idx)
+				break
+			}
+		}
+	}
+
+	return forced, nil
+}
+
+// forced tells for each position whether its field is forced: the positions assigned already are, and so
+// is a position left with a single candidate that lets the positions left all be given a different field.
+func (e *elimination) forced() []bool {
+	forced := make([]bool, len(e.candidates))
+	for fieldPos, row := range e.candidates {
+		if e.fieldOf[fieldPos] >= 0 {
+			forced[fieldPos] = true
+			continue
+		}
+
+		count := 0
+		for idx := row.next(0); idx >= 0; idx = row.next(idx + 1) {
+			if e.feasible(fieldPos, idx) {
+				count++
+			}
+		}
+		forced[fieldPos] = count == 1
+	}
+
+	return forced
+}
+
+// search layers the positions left by their distance from the free positions, following the candidates
+// from the positions and the matching back from the fields. It tells whether a free field was reached, in
+// which case there is an augmenting path.
+func (m *matching) search() bool {
+	queue := make([]int, 0, len(m.fieldOf))
+	for fieldPos := range m.fieldOf {
+		m.layer[fieldPos] = unmatched
+
+		if m.e.fieldOf[fieldPos] < 0 && m.fieldOf[fieldPos] == unmatched {
+			m.layer[fieldPos] = 0
+			queue = append(queue, fieldPos)
+		}
+	}
+
+	found := false
+	for len(queue) > 0 {
+		fieldPos := queue[0]
+		queue = queue[1:]
+
+		row := m.e.candidates[fieldPos]
+		for idx := row.next(0); idx >= 0; idx = row.next(idx + 1) {
+			if m.e.positionOf[idx] >= 0 {
+				continue
+			}
+
+			next := m.positionOf[idx]
+			if next == unmatched {
+				found = true
+			} else if m.layer[next] == unmatched {
+				m.layer[next] = m.layer[fieldPos] + 1
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	return found
+}
+
+// augment follows the layers from the position to a free field, and flips the matching along the path.
+// It tells whether such a path was found.
+func (m *matching) augment(fieldPos int) bool {
+	row := m.e.candidates[fieldPos]
+	for idx := row.next(0); idx >= 0; idx = row.next(idx + 1) {
+		if m.e.positionOf[idx] >= 0 {
+			continue
+		}
+
+		next := m.positionOf[idx]
+		if next == unmatched || (m.layer[next] == m.layer[fieldPos]+1 && m.augment(next)) {
+			m.fieldOf[fieldPos] = idx
+			m.positionOf[idx] = fieldPos
+			return true
+		}
+	}
+
+	// The position leads nowhere, no need to try it again during this phase.
+	m.layer[fieldPos] = unmatched
+
+	return false
+}
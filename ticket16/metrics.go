@@ -0,0 +1,86 @@
+package ticket16
+
+import "sort"
+
+// ErrorMetrics breaks the ticket scanning error rate down, see WithMetrics.
+type ErrorMetrics struct {
+	// Discarded counts the nearby tickets left out of the inference of the ordering: the invalid ones and
+	// the ragged ones. The tolerated tickets were kept, so they aren't counted.
+	Discarded int
+
+	// Rules tells how many values of the nearby tickets each rule accepts, in the order of the rules.
+	Rules []RuleMetric
+
+	// Positions tells how many invalid values each position of the nearby tickets holds, the position
+	// holding the most first. The positions holding none are left out.
+	Positions []PositionMetric
+}
+
+// RuleMetric tells how many values of the nearby tickets a rule accepts.
+type RuleMetric struct {
+	Field    string // Field is the name of the rule.
+	Accepted int    // Accepted counts the values the rule accepts.
+}
+
+// PositionMetric tells how many invalid values of the nearby tickets a position holds.
+type PositionMetric struct {
+	Position int   // Position is the 0-based position in the tickets.
+	Invalid  int   // Invalid counts the invalid values the position holds.
+	Sum      int64 // Sum is the share of the position in the error rate.
+}
+
+// errorMetrics computes the metrics of the nearby tickets of the notes, once validated against the rules.
+func errorMetrics(notes *Notes, validation Validation, rules []Rule) *ErrorMetrics {
+	metrics := &ErrorMetrics{
+		Discarded: len(validation.Invalid) + len(validation.Ragged),
+		Rules:     make([]RuleMetric, len(rules)),
+	}
+
+	for idx, rule := range rules {
+		metrics.Rules[idx] = RuleMetric{Field: rule.Name(), Accepted: acceptedValues(rule, notes.NearbyTickets)}
+	}
+
+	byPosition := map[int]*PositionMetric{}
+	for _, invalids := range [][]InvalidTicket{validation.Invalid, validation.Tolerated} {
+		for _, invalid := range invalids {
+			for _, value := range invalid.Values {
+				position := byPosition[value.Position]
+				if position == nil {
+					position = &PositionMetric{Position: value.Position}
+					byPosition[value.Position] = position
+				}
+
+				position.Invalid++
+				position.Sum += value.Value
+			}
+		}
+	}
+
+	for _, position := range byPosition {
+		metrics.Positions = append(metrics.Positions, *position)
+	}
+	sort.Slice(metrics.Positions, func(i, j int) bool {
+		a, b := metrics.Positions[i], metrics.Positions[j]
+		if a.Invalid != b.Invalid {
+			return a.Invalid > b.Invalid
+		}
+
+		return a.Position < b.Position
+	})
+
+	return metrics
+}
+
+// acceptedValues counts the values of the tickets that the rule accepts.
+func acceptedValues(rule Rule, tickets []Ticket) int {
+	accepted := 0
+	for _, ticket := range tickets {
+		for fieldPos, value := range ticket.Values {
+			if ticket.Known(fieldPos) && rule.Matches(value) {
+				accepted++
+			}
+		}
+	}
+
+	return accepted
+}
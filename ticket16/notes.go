@@ -0,0 +1,544 @@
+package ticket16
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"unicode"
+)
+
+// section identifies which part of the notes is currently being read.
+type section int
+
+const (
+	sectionRules section = iota
+	sectionYourTicket
+	sectionNearbyTickets
+)
+
+// ParseOptions controls how the notes are parsed.
+type ParseOptions struct {
+	// Strict turns every structural problem into an error: malformed lines, sections in the wrong order,
+	// headers with unexpected text and blank lines anywhere else than before a section header or at the
+	// end of the notes. When Strict is false, the parser recovers where it can and records a warning.
+	Strict bool
+
+	// YourTicketHeader and NearbyTicketsHeader are the markers starting the ticket sections, without the
+	// trailing colon. They default to YourTicket and NearbyTickets when empty.
+	YourTicketHeader    string
+	NearbyTicketsHeader string
+
+	// PrefixedLiterals allows the ticket values and the range bounds to be written in hexadecimal (0x1F)
+	// or binary (0b1010) besides decimal. The base is detected from the prefix of each number.
+	PrefixedLiterals bool
+
+	// UnknownValues allows the ticket values to be written "?", or left empty, when they couldn't be read.
+	// They are recorded in TicketOf.Unknown, and match every rule.
+	UnknownValues bool
+
+	// Bounds tells whether the bounds of the "<min>-<max>" ranges belong to the ranges. It defaults to
+	// BoundsInclusive. With the other policies, the ranges may be written in interval notation too, where
+	// a square bracket includes the bound and a parenthesis excludes it, e.g. "[1,5)".
+	Bounds BoundPolicy
+
+	// Duplicates tells what to do when a field has more than one rule. It defaults to DuplicateError.
+	Duplicates DuplicatePolicy
+
+	// SkipMalformed makes the parser skip the rules and tickets that can't be parsed instead of failing.
+	// Every skipped line is reported as a warning. It has no effect in strict mode.
+	SkipMalformed bool
+
+	// Ragged tells what to do with the nearby tickets whose number of values differs from your ticket.
+	// It defaults to RaggedError.
+	Ragged RaggedPolicy
+
+	// MaxLineLength is the length in bytes of the longest line accepted, so that a corrupted input can't
+	// use up all the memory. Zero means that lines of any length are accepted.
+	MaxLineLength int
+
+	// KeepSources keeps the line and the text of each nearby ticket in Notes.Sources, so that the tickets
+	// can be pointed at in the input. It costs a copy of every ticket line, and only applies to the whole
+	// notes in the text format, read by ParseNotes, not to the ticket dumps.
+	KeepSources bool
+}
+
+// yourTicketHeader returns the marker starting the "your ticket" section.
+func (o ParseOptions) yourTicketHeader() string {
+	if o.YourTicketHeader == "" {
+		return YourTicket
+	}
+
+	return o.YourTicketHeader
+}
+
+// nearbyTicketsHeader returns the marker starting the "nearby tickets" section.
+func (o ParseOptions) nearbyTicketsHeader() string {
+	if o.NearbyTicketsHeader == "" {
+		return NearbyTickets
+	}
+
+	return o.NearbyTicketsHeader
+}
+
+// newLineScanner creates a scanner reading the notes line by line. Its buffer grows as needed, up to
+// ParseOptions.MaxLineLength, so that tickets with thousands of values can be read.
+func newLineScanner(r io.Reader, opts ParseOptions) *bufio.Scanner {
+	maxLength := opts.MaxLineLength
+	if maxLength <= 0 {
+		maxLength = math.MaxInt
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxLength)
+
+	return scanner
+}
+
+// scanError adds the line number to the errors of the scanner. The line that is too long is the one
+// following the last line read.
+func scanError(err error, lineNo int, opts ParseOptions) error {
+	if errors.Is(err, bufio.ErrTooLong) {
+		return &ParseError{Line: lineNo + 1, Msg: fmt.Sprintf("line is longer than %d bytes", opts.MaxLineLength)}
+	}
+
+	return err
+}
+
+// Notes stores everything found in the puzzle input.
+type Notes struct {
+	Configs       []Configuration
+	MyTicket      Ticket
+	NearbyTickets []Ticket
+
+	// Warnings lists the problems the parser recovered from in lenient mode. It is always empty in strict mode.
+	Warnings []*ParseError
+
+	// Skipped counts the malformed lines dropped because of ParseOptions.SkipMalformed, and the ragged
+	// tickets dropped because of RaggedSkip. Each of them is also listed in Warnings.
+	Skipped int
+
+	// Sources tells where each nearby ticket comes from in the input, by index, when the notes were read
+	// with ParseOptions.KeepSources. It is nil otherwise.
+	Sources []TicketSource
+}
+
+// TicketSource tells where a ticket comes from in the input.
+type TicketSource struct {
+	Line int    // Line is the 1-based number of the line of the ticket.
+	Text string // Text is the line itself, without its trailing spaces.
+}
+
+// notesParser keeps the state needed while reading the notes line by line.
+type notesParser struct {
+	opts    ParseOptions
+	handler NotesHandler
+	configs []Configuration // Rules read so far, handed over once the rules section is over.
+	emitted bool            // Whether the rules have been handed over.
+
+	partial       bool        // Whether the ticket sections are optional.
+	ruleLines     map[int]int // Line number of each rule, by index in configs.
+	section       section
+	seenSections  map[section]bool
+	hasMyTicket   bool
+	width         int    // Number of values of your ticket, or of the first nearby ticket when yours is missing.
+	pendingBlank  int    // Line number of a blank line that is not yet known to be in place, 0 if none.
+	previousBlank bool   // Whether the previous line was blank.
+	pendingText   string // Raw content of the pending blank line.
+
+	arena valueArena // arena holds the values of the nearby tickets.
+}
+
+// ParseNotes reads the whole notes document from the reader. In strict mode the first problem found is
+// returned as a ParseError. In lenient mode the recoverable problems are recorded in Notes.Warnings instead.
+func ParseNotes(r io.Reader, opts ParseOptions) (*Notes, error) {
+	return parseNotes(r, opts, false)
+}
+
+// ParseRules reads a notes document in which the "your ticket" and "nearby tickets" sections are optional.
+// It is meant for rules files whose tickets come from somewhere else.
+func ParseRules(r io.Reader, opts ParseOptions) (*Notes, error) {
+	return parseNotes(r, opts, true)
+}
+
+// parseNotes collects everything the streaming parser finds. When partial is true, the ticket sections
+// are optional.
+func parseNotes(r io.Reader, opts ParseOptions, partial bool) (*Notes, error) {
+	notes := &Notes{}
+	handler := NotesHandler{
+		Configurations: func(configs []Configuration) error {
+			notes.Configs = configs
+			return nil
+		},
+		MyTicket: func(ticket Ticket) error {
+			notes.MyTicket = ticket
+			return nil
+		},
+		NearbyTicket: func(ticket Ticket) error {
+			notes.NearbyTickets = append(notes.NearbyTickets, ticket)
+			return nil
+		},
+		Source: func(source TicketSource) {
+			notes.Sources = append(notes.Sources, source)
+		},
+		Warning: func(warning *ParseError) {
+			notes.Warnings = append(notes.Warnings, warning)
+		},
+		Skipped: func(warning *ParseError) {
+			notes.Warnings = append(notes.Warnings, warning)
+			notes.Skipped++
+		},
+	}
+
+	if err := streamNotes(r, opts, partial, handler); err != nil {
+		return nil, err
+	}
+
+	return notes, nil
+}
+
+// streamNotes reads the notes line by line and hands every part over as soon as it is complete.
+func streamNotes(r io.Reader, opts ParseOptions, partial bool, handler NotesHandler) error {
+	p := &notesParser{
+		opts:         opts,
+		handler:      handler,
+		partial:      partial,
+		ruleLines:    make(map[int]int),
+		section:      sectionRules,
+		seenSections: map[section]bool{sectionRules: true},
+	}
+
+	// Create a reader to read line by line
+	scanner := newLineScanner(r, opts)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+
+		if err := p.parseLine(lineNo, scanner.Bytes()); err != nil {
+			return err
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return scanError(err, lineNo, opts)
+	}
+
+	return p.finish()
+}
+
+// emitRules hands the rules over once the rules section is over. Duplicated fields may be merged with
+// a rule found earlier, so no rule is final until the whole section has been read.
+func (p *notesParser) emitRules() error {
+	if p.emitted {
+		return nil
+	}
+	p.emitted = true
+
+	if p.handler.Configurations == nil {
+		return nil
+	}
+
+	return p.handler.Configurations(p.configs)
+}
+
+// problem reports a structural problem. It returns the problem as an error in strict mode,
+// otherwise it records it as a warning and returns nil.
+func (p *notesParser) problem(line int, text string, format string, args ...interface{}) error {
+	err := &ParseError{Line: line, Text: text, Msg: fmt.Sprintf(format, args...)}
+
+	if p.opts.Strict {
+		return err
+	}
+
+	if p.handler.Warning != nil {
+		p.handler.Warning(err)
+	}
+	return nil
+}
+
+// malformed reports a line that can't be parsed. With SkipMalformed in lenient mode, the line is
+// reported as skipped and nil is returned. Otherwise the error is returned with its location.
+func (p *notesParser) malformed(err error, lineNo int, rawLine string) error {
+	err = atLine(err, lineNo, rawLine)
+
+	skipped, ok := skippedLine(err, p.opts)
+	if !ok {
+		return err
+	}
+
+	if p.handler.Skipped != nil {
+		p.handler.Skipped(skipped)
+	}
+	return nil
+}
+
+// ragged reports a nearby ticket whose number of values differs from your ticket. With RaggedSkip, the
+// line is reported as skipped and nil is returned. Otherwise the error is returned with its location.
+func (p *notesParser) ragged(lineNo int, rawLine string, values int) error {
+	err := &ParseError{Line: lineNo, Text: rawLine, Msg: fmt.Sprintf("ticket has %d values instead of %d", values, p.width)}
+	if p.opts.Ragged != RaggedSkip {
+		return err
+	}
+
+	err.Msg += ", line skipped"
+	if p.handler.Skipped != nil {
+		p.handler.Skipped(err)
+	}
+	return nil
+}
+
+// skippedLine turns the error of a malformed line into the warning reporting that the line is skipped.
+// It returns false when the line must not be skipped.
+func skippedLine(err error, opts ParseOptions) (*ParseError, bool) {
+	parseErr, ok := err.(*ParseError)
+	if !ok || opts.Strict || !opts.SkipMalformed {
+		return nil, false
+	}
+
+	skipped := *parseErr
+	skipped.Msg += ", line skipped"
+	return &skipped, true
+}
+
+// parseLine processes a single raw line of the notes. The line is only valid until the next one is read,
+// which lets the scanner reuse its buffer: it is only copied to a string for the rules, your ticket and the
+// errors, not for the nearby tickets unless their sources are kept.
+func (p *notesParser) parseLine(lineNo int, rawLine []byte) error {
+	// Drop the carriage return left by Windows line endings and any trailing whitespace.
+	// Leading whitespace is kept so that the reported columns match the raw line.
+	line := bytes.TrimRightFunc(rawLine, unicode.IsSpace)
+	header := bytes.TrimSpace(line)
+
+	// Blank lines are only known to be in place once we see what follows them.
+	if len(header) == 0 {
+		if lineNo == 1 || p.previousBlank {
+			if err := p.problem(lineNo, string(rawLine), "unexpected blank line"); err != nil {
+				return err
+			}
+		} else {
+			p.pendingBlank = lineNo
+			p.pendingText = string(rawLine)
+		}
+
+		p.previousBlank = true
+		return nil
+	}
+	p.previousBlank = false
+
+	// Check if we are reading "your ticket" or "nearby tickets". Other than that, we are reading
+	// the data of the current section.
+	yourTicketHeader, nearbyTicketsHeader := p.opts.yourTicketHeader(), p.opts.nearbyTicketsHeader()
+	if hasPrefix(header, yourTicketHeader) {
+		p.pendingBlank = 0
+		return p.startSection(lineNo, string(rawLine), string(header), sectionYourTicket, yourTicketHeader, sectionRules)
+	} else if hasPrefix(header, nearbyTicketsHeader) {
+		p.pendingBlank = 0
+		return p.startSection(lineNo, string(rawLine), string(header), sectionNearbyTickets, nearbyTicketsHeader, sectionYourTicket)
+	}
+
+	// A blank line followed by data is out of place.
+	if p.pendingBlank > 0 {
+		blankLine := p.pendingBlank
+		p.pendingBlank = 0
+
+		if err := p.problem(blankLine, p.pendingText, "unexpected blank line"); err != nil {
+			return err
+		}
+	}
+
+	// Reading the data and process based on the section.
+	switch p.section {
+	case sectionRules:
+		// Process the configuration
+		newConfig, err := parseConfiguration(string(line), p.opts)
+		if err != nil {
+			return p.malformed(err, lineNo, string(rawLine))
+		}
+
+		configs, existingIdx, err := addConfiguration(p.configs, newConfig, p.opts.Duplicates)
+		if err != nil {
+			return &ParseError{
+				Line: lineNo,
+				Text: string(rawLine),
+				Msg:  fmt.Sprintf("%s (first defined on line %d)", err, p.ruleLines[existingIdx]),
+			}
+		}
+
+		if existingIdx < 0 {
+			p.ruleLines[len(configs)-1] = lineNo
+		}
+		p.configs = configs
+	case sectionYourTicket:
+		// Process our own ticket.
+		myTicket, err := parseTicketIn(line, p.opts, nil)
+		if err != nil {
+			return p.malformed(err, lineNo, string(rawLine))
+		}
+
+		if p.hasMyTicket {
+			// Only the first ticket is ours, the rest is ignored.
+			return p.problem(lineNo, string(rawLine), "more than one ticket in the %q section", p.opts.yourTicketHeader())
+		}
+
+		p.hasMyTicket = true
+		if p.width > 0 && len(myTicket.Values) != p.width {
+			return &ParseError{Line: lineNo, Text: string(rawLine), Msg: fmt.Sprintf("ticket has %d values instead of %d like the nearby tickets", len(myTicket.Values), p.width)}
+		}
+		p.width = len(myTicket.Values)
+
+		if p.handler.MyTicket != nil {
+			return p.handler.MyTicket(myTicket)
+		}
+	case sectionNearbyTickets:
+		// Process the nearby ticket
+		nearbyTicket, err := parseTicketIn(line, p.opts, &p.arena)
+		if err != nil {
+			return p.malformed(err, lineNo, string(rawLine))
+		}
+
+		if p.width == 0 {
+			p.width = len(nearbyTicket.Values)
+		} else if len(nearbyTicket.Values) != p.width {
+			return p.ragged(lineNo, string(rawLine), len(nearbyTicket.Values))
+		}
+
+		if p.opts.KeepSources && p.handler.Source != nil {
+			p.handler.Source(TicketSource{Line: lineNo, Text: string(line)})
+		}
+
+		if p.handler.NearbyTicket != nil {
+			return p.handler.NearbyTicket(nearbyTicket)
+		}
+	}
+
+	return nil
+}
+
+// startSection switches the parser to the given section after checking that the header is well formed
+// and that the section comes right after the expected previous section.
+func (p *notesParser) startSection(lineNo int, rawLine string, header string, next section, marker string, previous section) error {
+	if header != marker+":" {
+		if err := p.problem(lineNo, rawLine, "section header should be %q", marker+":"); err != nil {
+			return err
+		}
+	}
+
+	if p.seenSections[next] {
+		if err := p.problem(lineNo, rawLine, "%q section appears more than once", marker); err != nil {
+			return err
+		}
+	} else if p.section != previous {
+		if err := p.problem(lineNo, rawLine, "%q section is out of order", marker); err != nil {
+			return err
+		}
+	}
+
+	p.section = next
+	p.seenSections[next] = true
+
+	// No rule can follow a section header, so the rules are complete.
+	return p.emitRules()
+}
+
+// finish checks that all the sections have been found once the whole notes have been read.
+func (p *notesParser) finish() error {
+	if err := p.emitRules(); err != nil {
+		return err
+	}
+
+	if p.partial {
+		return nil
+	}
+
+	if !p.hasMyTicket {
+		if err := p.problem(0, "", "%q section has no ticket", p.opts.yourTicketHeader()); err != nil {
+			return err
+		}
+	}
+
+	if !p.seenSections[sectionNearbyTickets] {
+		if err := p.problem(0, "", "%q section is missing", p.opts.nearbyTicketsHeader()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// NewNotes creates the notes from rules and tickets that were obtained separately.
+func NewNotes(configs []Configuration, myTicket Ticket, nearbyTickets []Ticket) *Notes {
+	return &Notes{
+		Configs:       configs,
+		MyTicket:      myTicket,
+		NearbyTickets: nearbyTickets,
+	}
+}
+
+// WithTickets returns a copy of the notes that keeps the rules, your ticket and the warnings, but
+// whose nearby tickets are replaced by the given ones. It allows one set of rules to be checked
+// against many independent ticket dumps.
+func (n *Notes) WithTickets(nearbyTickets []Ticket) *Notes {
+	notes := NewNotes(n.Configs, n.MyTicket, nearbyTickets)
+	notes.Warnings = n.Warnings
+	notes.Skipped = n.Skipped
+
+	return notes
+}
+
+// ParseTickets reads a ticket dump: one ticket per line, optionally preceded by the nearby tickets
+// header. Blank lines are ignored, except in strict mode where they are only allowed at the end.
+// The lines skipped because of ParseOptions.SkipMalformed are returned as warnings.
+func ParseTickets(r io.Reader, opts ParseOptions) ([]Ticket, []*ParseError, error) {
+	tickets := make([]Ticket, 0)
+	warnings := make([]*ParseError, 0)
+	var arena valueArena
+
+	scanner := newLineScanner(r, opts)
+	lineNo := 0
+	blankLine := 0
+	for scanner.Scan() {
+		lineNo++
+
+		rawLine := scanner.Bytes()
+		line := bytes.TrimRightFunc(rawLine, unicode.IsSpace)
+
+		if len(bytes.TrimSpace(line)) == 0 {
+			if blankLine == 0 {
+				blankLine = lineNo
+			}
+			continue
+		}
+
+		if opts.Strict && blankLine > 0 {
+			return nil, nil, &ParseError{Line: blankLine, Msg: "unexpected blank line"}
+		}
+		blankLine = 0
+
+		// The header is only allowed on the first line.
+		if lineNo == 1 && hasPrefix(bytes.TrimSpace(line), opts.nearbyTicketsHeader()) {
+			continue
+		}
+
+		ticket, err := parseTicketIn(line, opts, &arena)
+		if err != nil {
+			err = atLine(err, lineNo, string(rawLine))
+
+			skipped, ok := skippedLine(err, opts)
+			if !ok {
+				return nil, nil, err
+			}
+
+			warnings = append(warnings, skipped)
+			continue
+		}
+		tickets = append(tickets, ticket)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, nil, scanError(err, lineNo, opts)
+	}
+
+	return tickets, warnings, nil
+}
@@ -0,0 +1,118 @@
+package ticket16
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// algorithms lists every ordering algorithm, which must all agree.
+var algorithms = []Algorithm{AlgorithmElimination, AlgorithmMatching, AlgorithmBacktrack}
+
+// letterCandidates returns the candidates of the positions among the fields named by the first letters,
+// each row listing the letters of the fields the position may hold.
+func letterCandidates(fields int, rows ...string) ([][]bool, []string) {
+	names := make([]string, fields)
+	for idx := range names {
+		names[idx] = string(rune('a' + idx))
+	}
+
+	candidates := make([][]bool, len(rows))
+	for fieldPos, row := range rows {
+		candidates[fieldPos] = make([]bool, fields)
+		for _, letter := range row {
+			candidates[fieldPos][letter-'a'] = true
+		}
+	}
+
+	return candidates, names
+}
+
+func TestAlgorithmsAgree(t *testing.T) {
+	tests := []struct {
+		name   string
+		fields int
+		rows   []string
+
+		want    string // want is the ordering as letters, empty when there is none.
+		wantErr error  // wantErr is the error of every algorithm, when there is no ordering.
+
+		// ambiguous tells that the elimination gives up before the ordering is forced.
+		ambiguous bool
+	}{
+		{name: "forced", fields: 3, rows: []string{"abc", "b", "bc"}, want: "abc"},
+		{name: "forced by field", fields: 3, rows: []string{"abc", "ab", "ab"}, want: "cab", ambiguous: true},
+		{name: "tie", fields: 2, rows: []string{"ab", "ab"}, want: "ab", ambiguous: true},
+		{name: "tie-break past a dead end", fields: 4, rows: []string{"abc", "abc", "ab", "cd"}, want: "acbd", ambiguous: true},
+		{name: "position without field", fields: 3, rows: []string{"a", "", "bc"}, wantErr: ErrUnsolvable},
+		{name: "field without position", fields: 3, rows: []string{"a", "ab"}, wantErr: ErrUnsolvable},
+		{name: "positions sharing a field", fields: 3, rows: []string{"a", "a", "abc"}, wantErr: ErrUnsolvable},
+		{name: "positions sharing fields after a tie", fields: 4, rows: []string{"abcd", "abcd", "ab", "ab", "ab"}, wantErr: ErrUnsolvable},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			candidates, fields := letterCandidates(test.fields, test.rows...)
+
+			var positions [][]int
+			for _, algorithm := range algorithms {
+				ordering, _, _, err := NewSolver(WithAlgorithm(algorithm)).inferOrdering(context.Background(), candidates, fields)
+
+				switch {
+				case algorithm == AlgorithmElimination && test.ambiguous:
+					if !errors.Is(err, ErrAmbiguousOrdering) {
+						t.Errorf("%s: error = %v, want %v", algorithm, err, ErrAmbiguousOrdering)
+					}
+				case test.wantErr != nil:
+					if !errors.Is(err, test.wantErr) {
+						t.Errorf("%s: error = %v, want %v", algorithm, err, test.wantErr)
+					}
+					var unsolvable *UnsolvableError
+					if errors.As(err, &unsolvable) {
+						positions = append(positions, unsolvable.Positions)
+					}
+				case err != nil:
+					t.Errorf("%s: unexpected error: %s", algorithm, err)
+				default:
+					if got := strings.Join(ordering, ""); got != test.want {
+						t.Errorf("%s: ordering = %s, want %s", algorithm, got, test.want)
+					}
+				}
+			}
+
+			// The unsolvable inputs name the same positions whatever the algorithm. The fields named are the
+			// ones left when the algorithm gives up, which the elimination narrows down.
+			for idx := 1; idx < len(positions); idx++ {
+				if !reflect.DeepEqual(positions[idx], positions[0]) {
+					t.Errorf("%s names the positions %v, %s the positions %v", algorithms[0], positions[0], algorithms[idx], positions[idx])
+				}
+			}
+		})
+	}
+}
+
+func TestAlgorithmsAgreeOnExample(t *testing.T) {
+	notes, err := ParseNotes(strings.NewReader(ExampleInput), ParseOptions{})
+	if err != nil {
+		t.Fatalf("ParseNotes failed: %s", err)
+	}
+
+	want, err := NewSolver(WithAlgorithm(AlgorithmBacktrack)).SolveNotes(notes)
+	if err != nil {
+		t.Fatalf("SolveNotes failed: %s", err)
+	}
+
+	for _, algorithm := range algorithms {
+		result, err := NewSolver(WithAlgorithm(algorithm)).SolveNotes(notes)
+		if err != nil {
+			t.Errorf("%s: SolveNotes failed: %s", algorithm, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(result.Ordering, want.Ordering) || result.Part1 != want.Part1 || result.Part2 != want.Part2 {
+			t.Errorf("%s: ordering %v, part 1 %d and part 2 %d, want %v, %d and %d", algorithm, result.Ordering, result.Part1, result.Part2, want.Ordering, want.Part1, want.Part2)
+		}
+	}
+}
@@ -0,0 +1,385 @@
+package ticket16
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// trimSpace removes the surrounding whitespace (including carriage returns) of the text found at the given
+// 0-based offset of a line. It returns the trimmed text and its adjusted offset.
+func trimSpace(text string, offset int) (string, int) {
+	trimmedLeft := strings.TrimLeftFunc(text, unicode.IsSpace)
+	offset += len(text) - len(trimmedLeft)
+
+	return strings.TrimRightFunc(trimmedLeft, unicode.IsSpace), offset
+}
+
+// trimSpaceBytes is like trimSpace, for a text that is still a slice of the line read.
+func trimSpaceBytes(text []byte, offset int) ([]byte, int) {
+	trimmedLeft := bytes.TrimLeftFunc(text, unicode.IsSpace)
+	offset += len(text) - len(trimmedLeft)
+
+	return bytes.TrimRightFunc(trimmedLeft, unicode.IsSpace), offset
+}
+
+// hasPrefix tells whether the text, still a slice of the line read, begins with the prefix.
+func hasPrefix(text []byte, prefix string) bool {
+	return len(text) >= len(prefix) && string(text[:len(prefix)]) == prefix
+}
+
+// parseConfiguration parses the Configuration string. It returns the Configuration object, or a ParseError
+// pointing to the offending column when the config string is malformed.
+// Whitespace around the field name, the ranges and the range bounds is ignored.
+func parseConfiguration(config string, opts ParseOptions) (Configuration, error) {
+	// Format is <Field>: <range> [or <range]... [not <range> [or <range>]...]...
+	// where <range> is <min>-<max>, <min>+, >=<min> or <=<max>.
+	// Get the Field first.
+	field, colonIdx, err := parseField(config)
+	if err != nil {
+		return Configuration{}, err
+	}
+
+	// Get the range string, by removing everything before the range indicator.
+	// We keep track of the offset so that errors can point to the right column.
+	offset := colonIdx + 1
+	rangesData := config[offset:]
+
+	// Separate the allowed ranges from the excluded ones by the " not " separator.
+	parts := strings.Split(rangesData, " not ")
+
+	validRanges, err := parseRanges(field, parts[0], offset, opts)
+	if err != nil {
+		return Configuration{}, err
+	}
+	offset += len(parts[0]) + len(" not ")
+
+	var exclusions []ValidRange
+	for _, part := range parts[1:] {
+		excludedRanges, err := parseRanges(field, part, offset, opts)
+		if err != nil {
+			return Configuration{}, err
+		}
+
+		exclusions = append(exclusions, excludedRanges...)
+		offset += len(part) + len(" not ")
+	}
+
+	return Configuration{
+		Field:      field,
+		Ranges:     validRanges,
+		Exclusions: exclusions,
+	}, nil
+}
+
+// parseField parses the field name at the start of the Configuration string. It returns the field name
+// and the index of the ":" separator following it. The field name may be written between double quotes,
+// with Go escape sequences (e.g. \" and \\), so that it can contain colons or start like a section header:
+//
+//	"arrival: gate": 1-5 or 8-19
+func parseField(config string) (string, int, error) {
+	trimmed, offset := trimSpace(config, 0)
+	if !strings.HasPrefix(trimmed, `"`) {
+		colonIdx := strings.Index(config, ":")
+		if colonIdx < 0 {
+			return "", 0, newParseError(0, "rule has no field name separator ':'")
+		}
+
+		field, _ := trimSpace(config[:colonIdx], 0)
+		return field, colonIdx, nil
+	}
+
+	// Look for the closing quote, skipping the escaped characters.
+	closingIdx := -1
+	for idx := offset + 1; idx < len(config); idx++ {
+		if config[idx] == '\\' {
+			idx++
+		} else if config[idx] == '"' {
+			closingIdx = idx
+			break
+		}
+	}
+	if closingIdx < 0 {
+		return "", 0, newParseError(offset, "quoted field name is not terminated")
+	}
+
+	field, err := strconv.Unquote(config[offset : closingIdx+1])
+	if err != nil {
+		return "", 0, newParseError(offset, "quoted field name has an invalid escape sequence")
+	}
+
+	// Only whitespace may separate the closing quote from the ":" separator.
+	rest, restOffset := trimSpace(config[closingIdx+1:], closingIdx+1)
+	if !strings.HasPrefix(rest, ":") {
+		return "", 0, newParseError(restOffset, "quoted field name must be followed by ':'")
+	}
+
+	return field, restOffset, nil
+}
+
+// parseRanges parses a list of ranges separated by " or ", found at the given 0-based offset of the line.
+// The field name is only used in the error messages.
+func parseRanges(field string, rangesData string, offset int, opts ParseOptions) ([]ValidRange, error) {
+	// Separate the range by " or " separator.
+	ranges := strings.Split(rangesData, " or ")
+
+	// Build the ValidRange for each Ranges.
+	validRanges := make([]ValidRange, len(ranges))
+
+	for idx, rawRng := range ranges {
+		rng, rngOffset := trimSpace(rawRng, offset)
+
+		validRange, err := parseRange(rng, rngOffset, opts)
+		if err != nil {
+			// Tell which rule the range belongs to.
+			parseErr := err.(*ParseError)
+			parseErr.Msg = fmt.Sprintf("rule %q: %s", field, parseErr.Msg)
+
+			return nil, parseErr
+		}
+		validRanges[idx] = validRange
+
+		// Move the offset to the next range, skipping the " or " separator.
+		offset += len(rawRng) + len(" or ")
+	}
+
+	return validRanges, nil
+}
+
+// parseValue parses a ticket value or a range bound. With the PrefixedLiterals option, the value may be
+// written in hexadecimal (0x1F) or binary (0b1010) too. A leading sign is accepted in all bases.
+func parseValue(data string, opts ParseOptions) (int64, error) {
+	if !opts.PrefixedLiterals {
+		return strconv.ParseInt(data, 10, 64)
+	}
+
+	sign, digits := "", data
+	if strings.HasPrefix(digits, "+") || strings.HasPrefix(digits, "-") {
+		sign, digits = digits[:1], digits[1:]
+	}
+
+	base := 10
+	if len(digits) > 2 && digits[0] == '0' {
+		switch digits[1] {
+		case 'x', 'X':
+			base = 16
+		case 'b', 'B':
+			base = 2
+		}
+	}
+
+	if base != 10 {
+		digits = digits[2:]
+
+		// The sign must come before the prefix, not after it.
+		if strings.HasPrefix(digits, "+") || strings.HasPrefix(digits, "-") {
+			return 0, &strconv.NumError{Func: "ParseInt", Num: data, Err: strconv.ErrSyntax}
+		}
+	}
+
+	return strconv.ParseInt(sign+digits, base, 64)
+}
+
+// maxDecimalDigits is the number of digits scanDecimal reads at most, so that their value fits in a uint64.
+const maxDecimalDigits = 19
+
+// scanDecimal reads the decimal integer with an optional sign that makes up the data, like
+// strconv.ParseInt in base 10. It returns false when the data is anything else, or doesn't fit in an
+// int64, or has more than maxDecimalDigits digits, leading zeros included.
+func scanDecimal(data []byte) (int64, bool) {
+	negative := false
+	if len(data) > 0 && (data[0] == '+' || data[0] == '-') {
+		negative = data[0] == '-'
+		data = data[1:]
+	}
+
+	if len(data) == 0 || len(data) > maxDecimalDigits {
+		return 0, false
+	}
+
+	var n uint64
+	for _, c := range data {
+		digit := c - '0'
+		if digit > 9 {
+			return 0, false
+		}
+		n = n*10 + uint64(digit)
+	}
+
+	if negative {
+		if n > 1<<63 {
+			return 0, false
+		}
+		return -int64(n), true
+	}
+
+	if n > 1<<63-1 {
+		return 0, false
+	}
+	return int64(n), true
+}
+
+// invalidIntReason explains why strconv failed to parse an integer.
+func invalidIntReason(err error) string {
+	if errors.Is(err, strconv.ErrRange) {
+		return "does not fit in a 64-bit integer"
+	}
+
+	return "is not an integer"
+}
+
+// parseTicket parses the Ticket string. It returns a Ticket object that contains
+// all the Values found inside the Ticket, or a ParseError pointing to the offending column
+// when any of the Values is not a 64-bit integer. Values may have a leading sign.
+// Whitespace around the Values is ignored. With the UnknownValues option, the Values may be
+// "?" or empty too.
+func parseTicket(ticketData string, opts ParseOptions) (Ticket, error) {
+	return parseTicketIn([]byte(ticketData), opts, nil)
+}
+
+// parseTicketIn is like parseTicket, but reads the line in place and takes the values from the arena, when
+// it isn't nil. The plain decimal values are scanned by hand, so that a line is parsed without allocating
+// anything but its values.
+func parseTicketIn(ticketData []byte, opts ParseOptions, arena *valueArena) (Ticket, error) {
+	count := bytes.Count(ticketData, []byte{','}) + 1
+	values := arena.alloc(count)
+	var unknown []bool
+
+	offset := 0
+	for idx := 0; idx < count; idx++ {
+		// Cut the next value without splitting the whole line, which would allocate the pieces.
+		rawDatum := ticketData[offset:]
+		if end := bytes.IndexByte(rawDatum, ','); end >= 0 {
+			rawDatum = rawDatum[:end]
+		}
+		datum, datumOffset := trimSpaceBytes(rawDatum, offset)
+
+		if opts.UnknownValues && isUnknownBytes(datum) {
+			if unknown == nil {
+				unknown = make([]bool, count)
+			}
+			unknown[idx] = true
+
+			offset += len(rawDatum) + 1
+			continue
+		}
+
+		value, ok := scanDecimal(datum)
+		if !ok {
+			// The other literals, and the errors, are left to strconv.
+			var err error
+			value, err = parseValue(string(datum), opts)
+			if err != nil {
+				return Ticket{}, newParseError(datumOffset, "ticket value '%s' %s", datum, invalidIntReason(err))
+			}
+		}
+
+		values[idx] = value
+
+		// Move the offset to the next value, skipping the "," separator.
+		offset += len(rawDatum) + 1
+	}
+
+	return Ticket{Values: values, Unknown: unknown}, nil
+}
+
+// arenaBlockSize is the number of values a valueArena allocates at once.
+const arenaBlockSize = 16384
+
+// valueArena hands out the values of the tickets from large blocks, instead of allocating each ticket on
+// its own, which saves the garbage collector from tracking millions of small slices. A block is freed once
+// none of its tickets is referenced anymore.
+type valueArena struct {
+	block []int64
+}
+
+// alloc returns n zeroed values. Their capacity is n, so that appending to them never overwrites the
+// values of the next ticket. A nil arena allocates them on their own.
+func (a *valueArena) alloc(n int) []int64 {
+	if a == nil || n > arenaBlockSize/4 {
+		return make([]int64, n)
+	}
+
+	if cap(a.block)-len(a.block) < n {
+		a.block = make([]int64, 0, arenaBlockSize)
+	}
+
+	start := len(a.block)
+	a.block = a.block[:start+n]
+
+	return a.block[start : start+n : start+n]
+}
+
+// isUnknown tells whether the ticket value, once trimmed, stands for a value that couldn't be read.
+func isUnknown(datum string) bool {
+	return datum == "" || datum == "?"
+}
+
+// isUnknownBytes is like isUnknown, for a value that is still a slice of the line read.
+func isUnknownBytes(datum []byte) bool {
+	return len(datum) == 0 || (len(datum) == 1 && datum[0] == '?')
+}
+
+// parseRange parses a single range found at the given 0-based offset of the line. The range is either
+// closed ("<min>-<max>") or open-ended ("<min>+", ">=<min>" or "<=<max>"). The bounds are 64-bit
+// integers and may have a leading sign, e.g. "-10--5" or "+3-+8". ParseOptions.Bounds may exclude the
+// bounds of the closed ranges, which may then be written in interval notation too, e.g. "[1,5)".
+func parseRange(rng string, offset int, opts ParseOptions) (ValidRange, error) {
+	// Interval notation only makes sense when the bounds may be excluded.
+	if opts.Bounds != BoundsInclusive && opts.Bounds != "" && isInterval(rng) {
+		return parseInterval(rng, offset, opts)
+	}
+
+	switch {
+	case strings.HasPrefix(rng, "<="):
+		max, err := parseBound(rng[2:], offset+2, "maximum", opts)
+		return ValidRange{Max: max, NoMin: true}, err
+	case strings.HasPrefix(rng, ">="):
+		min, err := parseBound(rng[2:], offset+2, "minimum", opts)
+		return ValidRange{Min: min, NoMax: true}, err
+	case strings.HasSuffix(rng, "+"):
+		min, err := parseBound(rng[:len(rng)-1], offset, "minimum", opts)
+		return ValidRange{Min: min, NoMax: true}, err
+	}
+
+	// Separate the range by "-" to get the minimum and maximum value. The minimum may itself start
+	// with a sign, which is not the separator.
+	dashIdx := -1
+	if len(rng) > 1 {
+		if idx := strings.Index(rng[1:], "-"); idx >= 0 {
+			dashIdx = idx + 1
+		}
+	}
+	if dashIdx < 0 {
+		return ValidRange{}, newParseError(offset, "invalid range '%s'", rng)
+	}
+
+	min, err := parseBound(rng[:dashIdx], offset, "minimum", opts)
+	if err != nil {
+		return ValidRange{}, err
+	}
+
+	max, err := parseBound(rng[dashIdx+1:], offset+dashIdx+1, "maximum", opts)
+	if err != nil {
+		return ValidRange{}, err
+	}
+
+	minExcluded, maxExcluded := opts.Bounds.exclusive()
+	return excludeBounds(rng, offset, min, max, minExcluded, maxExcluded)
+}
+
+// parseBound parses the range bound found at the given 0-based offset of the line. The name of the bound
+// is only used in the error messages.
+func parseBound(data string, offset int, name string, opts ParseOptions) (int64, error) {
+	data, offset = trimSpace(data, offset)
+
+	value, err := parseValue(data, opts)
+	if err != nil {
+		return 0, newParseError(offset, "range %s '%s' %s", name, data, invalidIntReason(err))
+	}
+
+	return value, nil
+}
@@ -0,0 +1,169 @@
+package ticket16
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseTicketBoundaries(t *testing.T) {
+	tests := []struct {
+		line string
+		want []int64
+	}{
+		{"9223372036854775807", []int64{math.MaxInt64}},
+		{"-9223372036854775808", []int64{math.MinInt64}},
+		{"+9223372036854775807", []int64{math.MaxInt64}},
+		{"0,-0,+0", []int64{0, 0, 0}},
+		{"+7, -3 ,12", []int64{7, -3, 12}},
+		{"0009223372036854775807", []int64{math.MaxInt64}},
+	}
+
+	for _, test := range tests {
+		ticket, err := parseTicket(test.line, ParseOptions{})
+		if err != nil {
+			t.Errorf("parseTicket(%q) failed: %s", test.line, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(ticket.Values, test.want) {
+			t.Errorf("parseTicket(%q) = %v, want %v", test.line, ticket.Values, test.want)
+		}
+	}
+}
+
+func TestParseTicketOverflow(t *testing.T) {
+	for _, line := range []string{
+		"9223372036854775808",
+		"+9223372036854775808",
+		"-9223372036854775809",
+		"1,99999999999999999999",
+	} {
+		_, err := parseTicket(line, ParseOptions{})
+		if !errors.Is(err, ErrParse) {
+			t.Errorf("parseTicket(%q) = %v, want a parse error", line, err)
+			continue
+		}
+
+		if !strings.Contains(err.Error(), "does not fit in a 64-bit integer") {
+			t.Errorf("parseTicket(%q) = %q, want an overflow error", line, err)
+		}
+	}
+}
+
+func TestParseTicketSigns(t *testing.T) {
+	for _, line := range []string{"--1", "+-1", "1-", "+", "-", "0x10"} {
+		if _, err := parseTicket(line, ParseOptions{}); !errors.Is(err, ErrParse) {
+			t.Errorf("parseTicket(%q) = %v, want a parse error", line, err)
+		}
+	}
+
+	ticket, err := parseTicket("-0x10,+0b11", ParseOptions{PrefixedLiterals: true})
+	if err != nil {
+		t.Fatalf("parseTicket with prefixed literals failed: %s", err)
+	}
+	if want := []int64{-16, 3}; !reflect.DeepEqual(ticket.Values, want) {
+		t.Errorf("parseTicket with prefixed literals = %v, want %v", ticket.Values, want)
+	}
+}
+
+func TestParseRangeLimits(t *testing.T) {
+	tests := []struct {
+		rng  string
+		want ValidRange
+	}{
+		{"-9223372036854775808-9223372036854775807", ValidRange{Min: math.MinInt64, Max: math.MaxInt64}},
+		{"9223372036854775807-9223372036854775807", ValidRange{Min: math.MaxInt64, Max: math.MaxInt64}},
+		{"-9223372036854775808--9223372036854775808", ValidRange{Min: math.MinInt64, Max: math.MinInt64}},
+		{"-10--5", ValidRange{Min: -10, Max: -5}},
+		{"+3-+8", ValidRange{Min: 3, Max: 8}},
+		{"9223372036854775807+", ValidRange{Min: math.MaxInt64, NoMax: true}},
+		{"<=-9223372036854775808", ValidRange{Max: math.MinInt64, NoMin: true}},
+	}
+
+	for _, test := range tests {
+		got, err := parseRange(test.rng, 0, ParseOptions{})
+		if err != nil {
+			t.Errorf("parseRange(%q) failed: %s", test.rng, err)
+			continue
+		}
+
+		if got != test.want {
+			t.Errorf("parseRange(%q) = %+v, want %+v", test.rng, got, test.want)
+		}
+	}
+}
+
+func TestParseRangeOverflow(t *testing.T) {
+	for _, rng := range []string{
+		"0-9223372036854775808",
+		"-9223372036854775809-0",
+		"9223372036854775808+",
+	} {
+		_, err := parseRange(rng, 0, ParseOptions{})
+		if !errors.Is(err, ErrParse) || !strings.Contains(err.Error(), "does not fit in a 64-bit integer") {
+			t.Errorf("parseRange(%q) = %v, want an overflow error", rng, err)
+		}
+	}
+}
+
+func TestValidateBoundaries(t *testing.T) {
+	notes, err := ParseNotes(strings.NewReader(`low: -9223372036854775808--1
+high: 0-9223372036854775807
+
+your ticket:
+-5,5
+
+nearby tickets:
+-9223372036854775808,9223372036854775807
+-1,0
+`), ParseOptions{})
+	if err != nil {
+		t.Fatalf("ParseNotes failed: %s", err)
+	}
+
+	result, err := SolveNotes(notes)
+	if err != nil {
+		t.Fatalf("SolveNotes failed: %s", err)
+	}
+
+	if result.Part1 != 0 {
+		t.Errorf("part 1 = %d, want 0", result.Part1)
+	}
+	if want := []string{"low", "high"}; !reflect.DeepEqual(result.Ordering, want) {
+		t.Errorf("ordering = %v, want %v", result.Ordering, want)
+	}
+}
+
+// ticketLine is a ticket line of the size of the puzzle input, with a sign and padding to skip.
+const ticketLine = "7,1,14,-3, 952,88,121,7,42,+19,3,501,999,12,870,6,57,310,411,26"
+
+func TestParseTicketLineAllocs(t *testing.T) {
+	var arena valueArena
+	line := []byte(ticketLine)
+
+	// The arena allocates a block for hundreds of lines, which rounds down to none per line.
+	allocs := testing.AllocsPerRun(1000, func() {
+		if _, err := parseTicketIn(line, ParseOptions{}, &arena); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("parsing a ticket line allocates %v times, want 0", allocs)
+	}
+}
+
+func BenchmarkParseTicketLine(b *testing.B) {
+	var arena valueArena
+	line := []byte(ticketLine)
+	b.SetBytes(int64(len(line)))
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := parseTicketIn(line, ParseOptions{}, &arena); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package ticket16
+
+import "sync"
+
+// ProgressStep names the step of a solve that a Progress reports on.
+type ProgressStep string
+
+const (
+	// ProgressValidate reports on the validation of the nearby tickets.
+	ProgressValidate ProgressStep = "validate"
+	// ProgressOrder reports on the inference of the ordering, after each round of the elimination.
+	ProgressOrder ProgressStep = "order"
+)
+
+// Progress tells how far a step of a long solve went, see WithProgress.
+type Progress struct {
+	Step ProgressStep
+
+	// Done counts the nearby tickets validated, or the positions whose field is known.
+	Done int
+
+	// Total is the number Done counts up to, or 0 when it isn't known, like the number of tickets streamed
+	// by SolveStream.
+	Total int
+}
+
+// WithProgress reports the progress of the validation and of the inference of the ordering to the callback,
+// every few thousand tickets and after each round of the elimination, so that the long solves aren't
+// silent. The calls never overlap, but may come from the goroutines of the solver, so the callback should
+// return quickly.
+func WithProgress(report func(progress Progress)) Option {
+	return func(s *Solver) {
+		s.progress = report
+	}
+}
+
+// Round describes a round of the elimination inferring the ordering, see WithRoundTrace.
+type Round struct {
+	// Number counts the rounds from 1. The components of the wide notes share the count, and their rounds
+	// may come in any order.
+	Number int
+
+	// Fields lists the fields whose position the round found, in the order it found them.
+	Fields []string
+
+	// Matched tells that the elimination was stuck, and that the bipartite matching picked the fields.
+	Matched bool
+}
+
+// WithRoundTrace reports each round of the elimination to the callback, with the fields it placed, to see
+// how the ordering was inferred. The backtracking runs no rounds. Like with WithProgress, the calls never
+// overlap, but may come from the goroutines of the solver.
+func WithRoundTrace(trace func(round Round)) Option {
+	return func(s *Solver) {
+		s.roundTrace = trace
+	}
+}
+
+// progressCounter adds up the progress of a step made by several goroutines, and reports it. A nil
+// counter counts nothing.
+type progressCounter struct {
+	mu       sync.Mutex
+	report   func(progress Progress) // report is nil when the solver has no callback.
+	progress Progress
+	rounds   int               // rounds counts the rounds of the elimination, see Result.Rounds.
+	trace    func(round Round) // trace is nil when the solver has no round trace.
+}
+
+// newProgress starts counting the progress of the step, up to total.
+func (s *Solver) newProgress(step ProgressStep, total int) *progressCounter {
+	return &progressCounter{report: s.progress, progress: Progress{Step: step, Total: total}, trace: s.roundTrace}
+}
+
+// add adds n to the progress and reports it.
+func (c *progressCounter) add(n int) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.progress.Done += n
+	if c.report != nil {
+		c.report(c.progress)
+	}
+}
+
+// restart forgets the progress and the rounds counted so far, when the step starts over.
+func (c *progressCounter) restart() {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.progress.Done = 0
+	c.rounds = 0
+}
+
+// round counts a round of the elimination, and returns its number.
+func (c *progressCounter) round() int {
+	if c == nil {
+		return 0
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.rounds++
+	return c.rounds
+}
+
+// tracing tells whether the rounds of the elimination are traced, so that they are only described when
+// they are.
+func (c *progressCounter) tracing() bool {
+	return c != nil && c.trace != nil
+}
+
+// traceRound reports a round of the elimination to the round trace.
+func (c *progressCounter) traceRound(round Round) {
+	if !c.tracing() {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.trace(round)
+}
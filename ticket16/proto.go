@@ -0,0 +1,328 @@
+package ticket16
+
+import (
+	"fmt"
+	"io"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Field numbers of the messages defined in ticket16.proto.
+const (
+	protoRangeMin = 1
+	protoRangeMax = 2
+
+	protoConfigField      = 1
+	protoConfigRanges     = 2
+	protoConfigExclusions = 3
+
+	protoTicketValues  = 1
+	protoTicketUnknown = 2
+
+	protoNotesConfigs       = 1
+	protoNotesMyTicket      = 2
+	protoNotesNearbyTickets = 3
+)
+
+// MarshalNotesProto encodes the notes as a ticket16.Notes protocol buffers message (see ticket16.proto).
+func MarshalNotesProto(notes *Notes) []byte {
+	var b []byte
+
+	for _, config := range notes.Configs {
+		b = protowire.AppendTag(b, protoNotesConfigs, protowire.BytesType)
+		b = protowire.AppendBytes(b, marshalConfigurationProto(config))
+	}
+
+	b = protowire.AppendTag(b, protoNotesMyTicket, protowire.BytesType)
+	b = protowire.AppendBytes(b, marshalTicketProto(notes.MyTicket))
+
+	for _, ticket := range notes.NearbyTickets {
+		b = protowire.AppendTag(b, protoNotesNearbyTickets, protowire.BytesType)
+		b = protowire.AppendBytes(b, marshalTicketProto(ticket))
+	}
+
+	return b
+}
+
+// marshalConfigurationProto encodes a ticket16.Configuration message.
+func marshalConfigurationProto(config Configuration) []byte {
+	var b []byte
+
+	b = protowire.AppendTag(b, protoConfigField, protowire.BytesType)
+	b = protowire.AppendString(b, config.Field)
+
+	for _, rng := range config.Ranges {
+		b = protowire.AppendTag(b, protoConfigRanges, protowire.BytesType)
+		b = protowire.AppendBytes(b, marshalRangeProto(rng))
+	}
+
+	for _, rng := range config.Exclusions {
+		b = protowire.AppendTag(b, protoConfigExclusions, protowire.BytesType)
+		b = protowire.AppendBytes(b, marshalRangeProto(rng))
+	}
+
+	return b
+}
+
+// marshalRangeProto encodes a ticket16.ValidRange message. Missing bounds are left out.
+func marshalRangeProto(rng ValidRange) []byte {
+	var b []byte
+
+	if !rng.NoMin {
+		b = protowire.AppendTag(b, protoRangeMin, protowire.VarintType)
+		b = protowire.AppendVarint(b, protowire.EncodeZigZag(rng.Min))
+	}
+
+	if !rng.NoMax {
+		b = protowire.AppendTag(b, protoRangeMax, protowire.VarintType)
+		b = protowire.AppendVarint(b, protowire.EncodeZigZag(rng.Max))
+	}
+
+	return b
+}
+
+// marshalTicketProto encodes a ticket16.Ticket message. The values are packed, as proto3 does by default.
+func marshalTicketProto(ticket Ticket) []byte {
+	var packed []byte
+	for _, value := range ticket.Values {
+		packed = protowire.AppendVarint(packed, protowire.EncodeZigZag(value))
+	}
+
+	var b []byte
+	b = protowire.AppendTag(b, protoTicketValues, protowire.BytesType)
+	b = protowire.AppendBytes(b, packed)
+
+	var unknown []byte
+	for fieldPos := range ticket.Values {
+		if !ticket.Known(fieldPos) {
+			unknown = protowire.AppendVarint(unknown, uint64(fieldPos))
+		}
+	}
+	if unknown != nil {
+		b = protowire.AppendTag(b, protoTicketUnknown, protowire.BytesType)
+		b = protowire.AppendBytes(b, unknown)
+	}
+
+	return b
+}
+
+// UnmarshalNotesProto decodes a ticket16.Notes protocol buffers message (see ticket16.proto).
+// Unknown fields are skipped.
+func UnmarshalNotesProto(b []byte) (*Notes, error) {
+	notes := &Notes{}
+
+	err := forEachProtoField(b, func(num protowire.Number, typ protowire.Type, value []byte) error {
+		switch num {
+		case protoNotesConfigs:
+			config, err := unmarshalConfigurationProto(value)
+			if err != nil {
+				return err
+			}
+			notes.Configs = append(notes.Configs, config)
+		case protoNotesMyTicket:
+			ticket, err := unmarshalTicketProto(value)
+			if err != nil {
+				return err
+			}
+			notes.MyTicket = ticket
+		case protoNotesNearbyTickets:
+			ticket, err := unmarshalTicketProto(value)
+			if err != nil {
+				return err
+			}
+			notes.NearbyTickets = append(notes.NearbyTickets, ticket)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid protobuf notes: %w", err)
+	}
+
+	return notes, nil
+}
+
+// DecodeNotesProto reads the whole reader and decodes it with UnmarshalNotesProto.
+func DecodeNotesProto(r io.Reader) (*Notes, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return UnmarshalNotesProto(b)
+}
+
+// unmarshalConfigurationProto decodes a ticket16.Configuration message.
+func unmarshalConfigurationProto(b []byte) (Configuration, error) {
+	config := Configuration{}
+
+	err := forEachProtoField(b, func(num protowire.Number, typ protowire.Type, value []byte) error {
+		switch num {
+		case protoConfigField:
+			config.Field = string(value)
+		case protoConfigRanges:
+			rng, err := unmarshalRangeProto(value)
+			if err != nil {
+				return err
+			}
+			config.Ranges = append(config.Ranges, rng)
+		case protoConfigExclusions:
+			rng, err := unmarshalRangeProto(value)
+			if err != nil {
+				return err
+			}
+			config.Exclusions = append(config.Exclusions, rng)
+		}
+
+		return nil
+	})
+
+	return config, err
+}
+
+// unmarshalRangeProto decodes a ticket16.ValidRange message. A missing bound makes the range open-ended.
+func unmarshalRangeProto(b []byte) (ValidRange, error) {
+	rng := ValidRange{NoMin: true, NoMax: true}
+
+	err := forEachProtoField(b, func(num protowire.Number, typ protowire.Type, value []byte) error {
+		switch num {
+		case protoRangeMin:
+			n, err := consumeSint64(typ, value)
+			rng.Min, rng.NoMin = n, false
+			return err
+		case protoRangeMax:
+			n, err := consumeSint64(typ, value)
+			rng.Max, rng.NoMax = n, false
+			return err
+		}
+
+		return nil
+	})
+
+	return rng, err
+}
+
+// unmarshalTicketProto decodes a ticket16.Ticket message. Both packed and unpacked values are accepted.
+func unmarshalTicketProto(b []byte) (Ticket, error) {
+	ticket := Ticket{Values: make([]int64, 0)}
+	var unknown []uint64
+
+	err := forEachProtoField(b, func(num protowire.Number, typ protowire.Type, value []byte) error {
+		if num == protoTicketUnknown {
+			positions, err := consumeUint32s(typ, value)
+			unknown = append(unknown, positions...)
+			return err
+		}
+
+		if num != protoTicketValues {
+			return nil
+		}
+
+		if typ == protowire.BytesType {
+			// Packed values.
+			for len(value) > 0 {
+				n, length := protowire.ConsumeVarint(value)
+				if length < 0 {
+					return protowire.ParseError(length)
+				}
+
+				ticket.Values = append(ticket.Values, protowire.DecodeZigZag(n))
+				value = value[length:]
+			}
+
+			return nil
+		}
+
+		n, err := consumeSint64(typ, value)
+		ticket.Values = append(ticket.Values, n)
+		return err
+	})
+	if err != nil {
+		return ticket, err
+	}
+
+	for _, fieldPos := range unknown {
+		if fieldPos >= uint64(len(ticket.Values)) {
+			return ticket, fmt.Errorf("unknown position %d out of the %d values", fieldPos, len(ticket.Values))
+		}
+
+		if ticket.Unknown == nil {
+			ticket.Unknown = make([]bool, len(ticket.Values))
+		}
+		ticket.Unknown[fieldPos] = true
+	}
+
+	return ticket, nil
+}
+
+// consumeUint32s decodes the values of a repeated uint32 field, packed or not.
+func consumeUint32s(typ protowire.Type, value []byte) ([]uint64, error) {
+	if typ == protowire.VarintType {
+		n, length := protowire.ConsumeVarint(value)
+		if length < 0 {
+			return nil, protowire.ParseError(length)
+		}
+
+		return []uint64{n}, nil
+	}
+
+	if typ != protowire.BytesType {
+		return nil, fmt.Errorf("unexpected wire type %d for a uint32 field", typ)
+	}
+
+	var values []uint64
+	for len(value) > 0 {
+		n, length := protowire.ConsumeVarint(value)
+		if length < 0 {
+			return nil, protowire.ParseError(length)
+		}
+
+		values = append(values, n)
+		value = value[length:]
+	}
+
+	return values, nil
+}
+
+// consumeSint64 decodes the value of a sint64 field.
+func consumeSint64(typ protowire.Type, value []byte) (int64, error) {
+	if typ != protowire.VarintType {
+		return 0, fmt.Errorf("unexpected wire type %d for a sint64 field", typ)
+	}
+
+	n, length := protowire.ConsumeVarint(value)
+	if length < 0 {
+		return 0, protowire.ParseError(length)
+	}
+
+	return protowire.DecodeZigZag(n), nil
+}
+
+// forEachProtoField calls fn for each field of the message. For length-delimited fields, value is the
+// content without the length prefix; for the other wire types it is the raw encoded value.
+func forEachProtoField(b []byte, fn func(num protowire.Number, typ protowire.Type, value []byte) error) error {
+	for len(b) > 0 {
+		num, typ, tagLength := protowire.ConsumeTag(b)
+		if tagLength < 0 {
+			return protowire.ParseError(tagLength)
+		}
+		b = b[tagLength:]
+
+		valueLength := protowire.ConsumeFieldValue(num, typ, b)
+		if valueLength < 0 {
+			return protowire.ParseError(valueLength)
+		}
+
+		value := b[:valueLength]
+		if typ == protowire.BytesType {
+			value, _ = protowire.ConsumeBytes(value)
+		}
+
+		if err := fn(num, typ, value); err != nil {
+			return err
+		}
+		b = b[valueLength:]
+	}
+
+	return nil
+}
@@ -0,0 +1,48 @@
+package ticket16
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RaggedPolicy tells what to do with the nearby tickets whose number of values differs from your ticket.
+type RaggedPolicy string
+
+const (
+	// RaggedError rejects the notes. It is the default policy.
+	RaggedError RaggedPolicy = "error"
+	// RaggedSkip leaves the ragged tickets out, reporting each of them.
+	RaggedSkip RaggedPolicy = "skip"
+)
+
+// ParseRaggedPolicy checks the name of a ragged ticket policy, as written on the command line.
+func ParseRaggedPolicy(name string) (RaggedPolicy, error) {
+	switch policy := RaggedPolicy(name); policy {
+	case RaggedError, RaggedSkip:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unknown ragged ticket policy %q", name)
+	}
+}
+
+// raggedTickets returns the indexes of the tickets whose number of values differs from width.
+func raggedTickets(tickets []Ticket, width int) []int {
+	var ragged []int
+	for idx, ticket := range tickets {
+		if len(ticket.Values) != width {
+			ragged = append(ragged, idx)
+		}
+	}
+
+	return ragged
+}
+
+// raggedError describes the ragged tickets, given by their indexes among the nearby tickets.
+func raggedError(ragged []int, width int) *ParseError {
+	indexes := make([]string, len(ragged))
+	for idx, ticketIdx := range ragged {
+		indexes[idx] = fmt.Sprint(ticketIdx)
+	}
+
+	return &ParseError{Msg: fmt.Sprintf("nearby tickets %s don't have %d values like your ticket", strings.Join(indexes, ", "), width)}
+}
@@ -0,0 +1,245 @@
+package ticket16
+
+import "sort"
+
+// RangeSetOf is a set of values made of ranges. The ranges are kept sorted, and the ranges that overlap
+// are merged, as well as the integer ranges that are adjacent (1-3 and 4-5 become 1-5). Membership is
+// checked by binary search, which is much faster than scanning the ranges of large rules.
+// The zero RangeSetOf is empty.
+type RangeSetOf[T Number] struct {
+	ranges []ValidRangeOf[T]
+}
+
+// RangeSet is the RangeSetOf of the parsed values.
+type RangeSet = RangeSetOf[int64]
+
+// NewRangeSet creates the set holding the values of all the given ranges. Empty ranges, whose minimum is
+// greater than their maximum, are ignored.
+func NewRangeSet[T Number](ranges ...ValidRangeOf[T]) RangeSetOf[T] {
+	sorted := make([]ValidRangeOf[T], 0, len(ranges))
+	for _, rng := range ranges {
+		if rng.NoMin || rng.NoMax || rng.Min <= rng.Max {
+			sorted = append(sorted, rng)
+		}
+	}
+
+	// Sort by minimum, the ranges without a minimum coming first.
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].NoMin || sorted[j].NoMin {
+			return sorted[i].NoMin && !sorted[j].NoMin
+		}
+
+		return sorted[i].Min < sorted[j].Min
+	})
+
+	merged := make([]ValidRangeOf[T], 0, len(sorted))
+	for _, rng := range sorted {
+		if len(merged) == 0 {
+			merged = append(merged, rng)
+			continue
+		}
+
+		last := &merged[len(merged)-1]
+		if !touches(*last, rng) {
+			merged = append(merged, rng)
+			continue
+		}
+
+		// Both ranges are merged into the last one, which starts first.
+		if rng.NoMax {
+			last.NoMax = true
+		} else if !last.NoMax && rng.Max > last.Max {
+			last.Max = rng.Max
+		}
+	}
+
+	return RangeSetOf[T]{ranges: merged}
+}
+
+// touches checks whether the range next, which doesn't start before the range last, overlaps it or
+// follows it right away.
+func touches[T Number](last ValidRangeOf[T], next ValidRangeOf[T]) bool {
+	if last.NoMax || next.NoMin || next.Min <= last.Max {
+		return true
+	}
+
+	// next.Min is greater than last.Max here, so next.Min-1 can't overflow.
+	return isInteger[T]() && next.Min-1 == last.Max
+}
+
+// isInteger checks whether T is an integer type, in which the ranges 1-3 and 4-5 are adjacent.
+func isInteger[T Number]() bool {
+	var one T = 1
+	return one/2 == 0
+}
+
+// Contains checks whether the value is in the set.
+func (s RangeSetOf[T]) Contains(value T) bool {
+	// Find the first range that doesn't end before the value.
+	idx := sort.Search(len(s.ranges), func(i int) bool {
+		return s.ranges[i].NoMax || s.ranges[i].Max >= value
+	})
+
+	return idx < len(s.ranges) && s.ranges[idx].Contains(value)
+}
+
+// Ranges returns the sorted and merged ranges of the set.
+func (s RangeSetOf[T]) Ranges() []ValidRangeOf[T] {
+	return append([]ValidRangeOf[T](nil), s.ranges...)
+}
+
+// Len returns the number of ranges of the set once merged.
+func (s RangeSetOf[T]) Len() int {
+	return len(s.ranges)
+}
+
+// RangeSet returns the set of the values allowed by the ranges of the configuration, exclusions aside.
+func (c ConfigurationOf[T]) RangeSet() RangeSetOf[T] {
+	return NewRangeSet(c.Ranges...)
+}
+
+// compiledConfig is a configuration whose ranges and exclusions were turned into range sets, so that
+// the values are checked by binary search. It is the Rule the solver uses for the configurations.
+type compiledConfig[T Number] struct {
+	field      string
+	ranges     RangeSetOf[T]
+	exclusions RangeSetOf[T]
+}
+
+// compileRules compiles every configuration, and appends the extra rules after them.
+func compileRules[T Number](configs []ConfigurationOf[T], extra []RuleOf[T]) []RuleOf[T] {
+	compiled := make([]RuleOf[T], 0, len(configs)+len(extra))
+	for _, config := range configs {
+		compiled = append(compiled, compiledConfig[T]{
+			field:      config.Field,
+			ranges:     NewRangeSet(config.Ranges...),
+			exclusions: NewRangeSet(config.Exclusions...),
+		})
+	}
+
+	return append(compiled, extra...)
+}
+
+// Name implements the RuleOf interface.
+func (c compiledConfig[T]) Name() string {
+	return c.field
+}
+
+// Matches implements the RuleOf interface, like ConfigurationOf.Contains.
+func (c compiledConfig[T]) Matches(value T) bool {
+	return c.ranges.Contains(value) && !c.exclusions.Contains(value)
+}
+
+// invalidValues returns the values of the ticket matching none of the rules, like TicketOf.Validate.
+func invalidValues[T Number](ticket TicketOf[T], rules []RuleOf[T]) []T {
+	return newValidIndex(rules).invalidValues(ticket)
+}
+
+// Intersect returns the set of the values in both sets.
+func (s RangeSetOf[T]) Intersect(other RangeSetOf[T]) RangeSetOf[T] {
+	var shared []ValidRangeOf[T]
+
+	for i, j := 0, 0; i < len(s.ranges) && j < len(other.ranges); {
+		a, b := s.ranges[i], other.ranges[j]
+		if rng, ok := intersectRange(a, b); ok {
+			shared = append(shared, rng)
+		}
+
+		// Move past the range ending first, it can't meet the next ranges of the other set.
+		if endsBefore(a, b) {
+			i++
+		} else {
+			j++
+		}
+	}
+
+	return RangeSetOf[T]{ranges: shared}
+}
+
+// Equal checks whether both sets hold the same values.
+func (s RangeSetOf[T]) Equal(other RangeSetOf[T]) bool {
+	if len(s.ranges) != len(other.ranges) {
+		return false
+	}
+
+	for idx, rng := range s.ranges {
+		if !sameRange(rng, other.ranges[idx]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// subtract returns the set of the values of s that are not in other. It only works on integers, whose
+// ranges stay closed once cut, and returns s as it is for floats.
+func (s RangeSetOf[T]) subtract(other RangeSetOf[T]) RangeSetOf[T] {
+	if !isInteger[T]() || len(other.ranges) == 0 {
+		return s
+	}
+
+	var pieces []ValidRangeOf[T]
+	for _, rng := range s.ranges {
+		cur, empty := rng, false
+
+		for _, cut := range other.ranges {
+			if _, ok := intersectRange(cur, cut); !ok {
+				continue
+			}
+
+			// Keep the values before the cut, if any: there are none when it starts at the lowest value of T.
+			if !cut.NoMin && (cur.NoMin || cur.Min < cut.Min) && cut.Min-1 < cut.Min {
+				pieces = append(pieces, ValidRangeOf[T]{Min: cur.Min, NoMin: cur.NoMin, Max: cut.Min - 1})
+			}
+
+			// Go on with the values after the cut, if any.
+			if cut.NoMax || !cur.NoMax && cur.Max <= cut.Max || cut.Max+1 < cut.Max {
+				empty = true
+				break
+			}
+			cur = ValidRangeOf[T]{Min: cut.Max + 1, Max: cur.Max, NoMax: cur.NoMax}
+		}
+
+		if !empty {
+			pieces = append(pieces, cur)
+		}
+	}
+
+	return NewRangeSet(pieces...)
+}
+
+// intersectRange returns the values in both ranges, and tells whether there are any.
+func intersectRange[T Number](a ValidRangeOf[T], b ValidRangeOf[T]) (ValidRangeOf[T], bool) {
+	rng := ValidRangeOf[T]{NoMin: a.NoMin && b.NoMin, NoMax: a.NoMax && b.NoMax}
+
+	switch {
+	case a.NoMin:
+		rng.Min = b.Min
+	case b.NoMin || a.Min > b.Min:
+		rng.Min = a.Min
+	default:
+		rng.Min = b.Min
+	}
+
+	switch {
+	case a.NoMax:
+		rng.Max = b.Max
+	case b.NoMax || a.Max < b.Max:
+		rng.Max = a.Max
+	default:
+		rng.Max = b.Max
+	}
+
+	return rng, rng.NoMin || rng.NoMax || rng.Min <= rng.Max
+}
+
+// endsBefore checks whether the range a ends before the range b.
+func endsBefore[T Number](a ValidRangeOf[T], b ValidRangeOf[T]) bool {
+	return !a.NoMax && (b.NoMax || a.Max < b.Max)
+}
+
+// sameRange checks whether both ranges hold the same values, whatever the bounds they don't use.
+func sameRange[T Number](a ValidRangeOf[T], b ValidRangeOf[T]) bool {
+	return a.NoMin == b.NoMin && a.NoMax == b.NoMax &&
+		(a.NoMin || a.Min == b.Min) && (a.NoMax || a.Max == b.Max)
+}
@@ -0,0 +1,97 @@
+package ticket16
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+// checkIndexAgrees checks that the index of the configurations, and the lookup tables of their rules, tell
+// the values apart like Contains does.
+func checkIndexAgrees[T Number](t *testing.T, configs []ConfigurationOf[T], values []T) {
+	t.Helper()
+
+	rules := compileRules(configs, nil)
+	index := newValidIndex(rules)
+	tables := denseRules(rules)
+
+	for _, value := range values {
+		want := false
+		for idx, config := range configs {
+			contains := config.Contains(value)
+			want = want || contains
+
+			if matches := rules[idx].Matches(value); matches != contains {
+				t.Errorf("rule %q matches %v: %t, want %t", config.Field, value, matches, contains)
+			}
+			if matches := matchesDense(rules[idx], tables[idx], value); matches != contains {
+				t.Errorf("table of rule %q matches %v: %t, want %t", config.Field, value, matches, contains)
+			}
+		}
+
+		if valid := index.valid(value); valid != want {
+			t.Errorf("index tells %v valid: %t, want %t", value, valid, want)
+		}
+	}
+}
+
+// everyValue returns every value of a type of at most 16 bits, from the lowest to the highest.
+func everyValue[T Number](lowest T, count int) []T {
+	values := make([]T, count)
+	for idx := range values {
+		values[idx] = lowest + T(idx)
+	}
+
+	return values
+}
+
+func TestIndexAtTypeBounds(t *testing.T) {
+	t.Run("uint8", func(t *testing.T) {
+		checkIndexAgrees(t, []ConfigurationOf[uint8]{
+			{Field: "a", Ranges: []ValidRangeOf[uint8]{{NoMin: true, Max: 83}, {Min: 43, Max: 227}}, Exclusions: []ValidRangeOf[uint8]{{Min: 0, Max: 128}}},
+			{Field: "b", Ranges: []ValidRangeOf[uint8]{{Min: 240, NoMax: true}}, Exclusions: []ValidRangeOf[uint8]{{Min: 250, Max: 255}}},
+		}, everyValue[uint8](0, 256))
+	})
+
+	t.Run("int8", func(t *testing.T) {
+		checkIndexAgrees(t, []ConfigurationOf[int8]{
+			{Field: "a", Ranges: []ValidRangeOf[int8]{{NoMin: true, NoMax: true}}, Exclusions: []ValidRangeOf[int8]{{Min: -128, Max: -100}, {Min: 100, Max: 127}}},
+		}, everyValue[int8](-128, 256))
+	})
+
+	t.Run("int64", func(t *testing.T) {
+		checkIndexAgrees(t, []ConfigurationOf[int64]{
+			{Field: "a", Ranges: []ValidRange{{NoMin: true, Max: 100}}, Exclusions: []ValidRange{{Min: math.MinInt64, Max: 50}}},
+			{Field: "b", Ranges: []ValidRange{{Min: 200, Max: 300}}},
+			{Field: "c", Ranges: []ValidRange{{Min: 1000, NoMax: true}}, Exclusions: []ValidRange{{Min: 2000, Max: math.MaxInt64}}},
+		}, []int64{math.MinInt64, math.MinInt64 + 1, -1, 0, 10, 50, 51, 60, 100, 101, 210, 1000, 1999, 2000, math.MaxInt64 - 1, math.MaxInt64})
+	})
+}
+
+func TestExclusionFromLowestValue(t *testing.T) {
+	notes, err := ParseNotes(strings.NewReader(`a: <=100 not -9223372036854775808-50
+b: 200-300
+
+your ticket:
+60,250
+
+nearby tickets:
+10,250
+60,210
+`), ParseOptions{})
+	if err != nil {
+		t.Fatalf("ParseNotes failed: %s", err)
+	}
+
+	result, err := SolveNotes(notes)
+	if err != nil {
+		t.Fatalf("SolveNotes failed: %s", err)
+	}
+
+	if result.Part1 != 10 {
+		t.Errorf("part 1 = %d, want 10", result.Part1)
+	}
+	if issues := AnalyzeRules(notes.Configs); len(issues) != 0 {
+		t.Errorf("AnalyzeRules = %v, want no issue", issues)
+	}
+}
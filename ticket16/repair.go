@@ -0,0 +1,240 @@
+package ticket16
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// RepairKind tells which slip a suggested repair undoes.
+type RepairKind string
+
+const (
+	RepairExtraDigit    RepairKind = "extra digit"    // RepairExtraDigit drops one of the digits.
+	RepairSwappedDigits RepairKind = "swapped digits" // RepairSwappedDigits swaps two neighbouring digits.
+	RepairMissingDigit  RepairKind = "missing digit"  // RepairMissingDigit inserts a digit.
+	RepairWrongDigit    RepairKind = "wrong digit"    // RepairWrongDigit changes one of the digits.
+	RepairNearestBound  RepairKind = "nearest bound"  // RepairNearestBound moves the value to the nearest value allowed.
+)
+
+// Repair is a valid value that an invalid value was likely meant to be.
+type Repair struct {
+	Value  int64      // Value is the suggested value.
+	Kind   RepairKind // Kind tells how the suggested value was found.
+	Fields []string   // Fields lists the fields whose rules allow the suggested value, in the order of the rules.
+}
+
+// String implements the fmt.Stringer interface, e.g. `85? likely extra digit` or `50? nearest bound of "seat"`.
+func (r Repair) String() string {
+	if r.Kind == RepairNearestBound {
+		return fmt.Sprintf("%d? %s of %s", r.Value, r.Kind, quoteFields(r.Fields))
+	}
+
+	return fmt.Sprintf("%d? likely %s", r.Value, r.Kind)
+}
+
+// SuggestRepairs returns the valid values that the value was likely meant to be: for each kind of typo, the
+// value closest to it that the typo turns into a valid value, then the nearest bound of the rules that came
+// closest to matching it. It returns nil when the value is valid.
+func SuggestRepairs(value int64, configs []Configuration) []Repair {
+	return suggestRepairs(value, compileRules(configs, nil))
+}
+
+// suggestRepairs is like SuggestRepairs, with configurations that are already compiled. The custom rules are
+// only used to check the typos, since they can't tell where their nearest bound is.
+func suggestRepairs(value int64, rules []RuleOf[int64]) []Repair {
+	if len(matchingFields(value, rules)) > 0 {
+		return nil
+	}
+
+	var repairs []Repair
+	seen := map[int64]bool{}
+
+	edits := []struct {
+		kind RepairKind
+		edit func(digits string) []string
+	}{
+		{RepairExtraDigit, extraDigit},
+		{RepairSwappedDigits, swappedDigits},
+		{RepairMissingDigit, missingDigit},
+		{RepairWrongDigit, wrongDigit},
+	}
+
+	sign, digits := "", strconv.FormatInt(value, 10)
+	if value < 0 {
+		sign, digits = "-", digits[1:]
+	}
+
+	for _, edit := range edits {
+		best := Repair{Kind: edit.kind}
+		found := false
+
+		for _, candidate := range edit.edit(digits) {
+			// Leading zeros are no typo anyone makes.
+			if len(candidate) > 1 && candidate[0] == '0' {
+				continue
+			}
+
+			repaired, err := strconv.ParseInt(sign+candidate, 10, 64)
+			if err != nil || seen[repaired] {
+				continue
+			}
+
+			fields := matchingFields(repaired, rules)
+			if len(fields) == 0 {
+				continue
+			}
+
+			if !found || absDiff(repaired, value) < absDiff(best.Value, value) {
+				best.Value, best.Fields, found = repaired, fields, true
+			}
+		}
+
+		if found {
+			seen[best.Value] = true
+			repairs = append(repairs, best)
+		}
+	}
+
+	if nearest, fields, ok := nearestBound(value, rules); ok && !seen[nearest] {
+		repairs = append(repairs, Repair{Value: nearest, Kind: RepairNearestBound, Fields: fields})
+	}
+
+	return repairs
+}
+
+// matchingFields returns the names of the rules matching the value, in the order of the rules.
+func matchingFields(value int64, rules []RuleOf[int64]) []string {
+	var fields []string
+	for _, rule := range rules {
+		if rule.Matches(value) {
+			fields = append(fields, rule.Name())
+		}
+	}
+
+	return fields
+}
+
+// nearestBound returns the value allowed by the configurations that is closest to the value, the lower one
+// on a tie, with the fields allowing it. It tells false when no configuration allows any value.
+func nearestBound(value int64, rules []RuleOf[int64]) (int64, []string, bool) {
+	var nearest int64
+	found := false
+
+	for _, rule := range rules {
+		config, ok := rule.(compiledConfig[int64])
+		if !ok {
+			continue
+		}
+
+		bound, ok := config.ranges.subtract(config.exclusions).Nearest(value)
+		if !ok {
+			continue
+		}
+
+		if d, best := absDiff(bound, value), absDiff(nearest, value); !found || d < best || (d == best && bound < nearest) {
+			nearest, found = bound, true
+		}
+	}
+
+	if !found {
+		return 0, nil, false
+	}
+
+	return nearest, matchingFields(nearest, rules), true
+}
+
+// Nearest returns the value of the set closest to the value, the lower one on a tie. It tells false when
+// the set is empty.
+func (s RangeSetOf[T]) Nearest(value T) (T, bool) {
+	var nearest, distance T
+	found := false
+
+	for _, rng := range s.ranges {
+		var bound T
+		switch {
+		case rng.Contains(value):
+			return value, true
+		case !rng.NoMin && value < rng.Min:
+			bound = rng.Min
+		default:
+			bound = rng.Max
+		}
+
+		d := bound - value
+		if d < 0 {
+			d = -d
+		}
+
+		// The ranges are sorted, so the first of two equally close bounds is the lower one.
+		if !found || d < distance {
+			nearest, distance, found = bound, d, true
+		}
+	}
+
+	return nearest, found
+}
+
+// absDiff returns how far apart the two values are.
+func absDiff(a int64, b int64) int64 {
+	if a < b {
+		return b - a
+	}
+
+	return a - b
+}
+
+// extraDigit returns the digits with one of them dropped.
+func extraDigit(digits string) []string {
+	if len(digits) < 2 {
+		return nil
+	}
+
+	candidates := make([]string, 0, len(digits))
+	for i := range digits {
+		candidates = append(candidates, digits[:i]+digits[i+1:])
+	}
+
+	return candidates
+}
+
+// swappedDigits returns the digits with two neighbouring ones swapped.
+func swappedDigits(digits string) []string {
+	candidates := make([]string, 0, len(digits))
+	for i := 0; i+1 < len(digits); i++ {
+		if digits[i] == digits[i+1] {
+			continue
+		}
+
+		swapped := []byte(digits)
+		swapped[i], swapped[i+1] = swapped[i+1], swapped[i]
+		candidates = append(candidates, string(swapped))
+	}
+
+	return candidates
+}
+
+// missingDigit returns the digits with one more inserted anywhere.
+func missingDigit(digits string) []string {
+	candidates := make([]string, 0, 10*(len(digits)+1))
+	for i := 0; i <= len(digits); i++ {
+		for digit := '0'; digit <= '9'; digit++ {
+			candidates = append(candidates, digits[:i]+string(digit)+digits[i:])
+		}
+	}
+
+	return candidates
+}
+
+// wrongDigit returns the digits with one of them changed.
+func wrongDigit(digits string) []string {
+	candidates := make([]string, 0, 9*len(digits))
+	for i := range digits {
+		for digit := byte('0'); digit <= '9'; digit++ {
+			if digit != digits[i] {
+				candidates = append(candidates, digits[:i]+string(digit)+digits[i+1:])
+			}
+		}
+	}
+
+	return candidates
+}
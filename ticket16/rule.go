@@ -0,0 +1,47 @@
+package ticket16
+
+// RuleOf is a constraint on the values of a field. The configurations read from the notes are rules
+// made of ranges, but any other constraint (parity, modulo, enumerated values...) can take part in the
+// validation and in the inference of the ordering by implementing this interface.
+type RuleOf[T Number] interface {
+	// Name returns the field the rule describes.
+	Name() string
+	// Matches checks whether the value is allowed for the field.
+	Matches(value T) bool
+}
+
+// Rule is the RuleOf of the parsed values.
+type Rule = RuleOf[int64]
+
+// Name implements the RuleOf interface.
+func (c ConfigurationOf[T]) Name() string {
+	return c.Field
+}
+
+// Matches implements the RuleOf interface, see Contains.
+func (c ConfigurationOf[T]) Matches(value T) bool {
+	return c.Contains(value)
+}
+
+// funcRule is a Rule whose values are checked by a function.
+type funcRule[T Number] struct {
+	name    string
+	matches func(value T) bool
+}
+
+// NewRule creates a rule for the given field, whose values are checked by the matches function:
+//
+//	even := NewRule("even seat", func(value int64) bool { return value%2 == 0 })
+func NewRule[T Number](name string, matches func(value T) bool) RuleOf[T] {
+	return funcRule[T]{name: name, matches: matches}
+}
+
+// Name implements the RuleOf interface.
+func (r funcRule[T]) Name() string {
+	return r.name
+}
+
+// Matches implements the RuleOf interface.
+func (r funcRule[T]) Matches(value T) bool {
+	return r.matches(value)
+}
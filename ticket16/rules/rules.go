@@ -0,0 +1,60 @@
+// Package rules builds the rules of the notes in code, without writing them in the puzzle format:
+//
+//	config := rules.New("departure time").Range(30, 100).Range(120, 200).Build()
+package rules
+
+import "github.com/handracs2007/advent_of_code_2020_day16/ticket16"
+
+// Builder builds a rule step by step. Every method adds to the rule and returns the builder, so that
+// the calls can be chained.
+type Builder struct {
+	config ticket16.Configuration
+}
+
+// New starts a rule for the given field.
+func New(field string) *Builder {
+	return &Builder{config: ticket16.Configuration{Field: field}}
+}
+
+// Range allows the values from min to max, both inclusive.
+func (b *Builder) Range(min int64, max int64) *Builder {
+	b.config.Ranges = append(b.config.Ranges, ticket16.ValidRange{Min: min, Max: max})
+	return b
+}
+
+// AtLeast allows the values greater than or equal to min, like the "min+" range of the puzzle format.
+func (b *Builder) AtLeast(min int64) *Builder {
+	b.config.Ranges = append(b.config.Ranges, ticket16.ValidRange{Min: min, NoMax: true})
+	return b
+}
+
+// AtMost allows the values lower than or equal to max, like the "<=max" range of the puzzle format.
+func (b *Builder) AtMost(max int64) *Builder {
+	b.config.Ranges = append(b.config.Ranges, ticket16.ValidRange{Max: max, NoMin: true})
+	return b
+}
+
+// Exclude rejects the values from min to max, both inclusive, even when they are in an allowed range.
+func (b *Builder) Exclude(min int64, max int64) *Builder {
+	b.config.Exclusions = append(b.config.Exclusions, ticket16.ValidRange{Min: min, Max: max})
+	return b
+}
+
+// Build returns the rule. The builder may still be used afterwards, without changing the returned rule.
+func (b *Builder) Build() ticket16.Configuration {
+	return ticket16.Configuration{
+		Field:      b.config.Field,
+		Ranges:     append([]ticket16.ValidRange(nil), b.config.Ranges...),
+		Exclusions: append([]ticket16.ValidRange(nil), b.config.Exclusions...),
+	}
+}
+
+// All builds every rule, in order.
+func All(builders ...*Builder) []ticket16.Configuration {
+	configs := make([]ticket16.Configuration, len(builders))
+	for idx, builder := range builders {
+		configs[idx] = builder.Build()
+	}
+
+	return configs
+}
@@ -0,0 +1,670 @@
+package ticket16
+
+import (
+	"context"
+	"io"
+	"math/big"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Contains checks whether the value is inside the range.
+func (r ValidRangeOf[T]) Contains(value T) bool {
+	return (r.NoMin || value >= r.Min) && (r.NoMax || value <= r.Max)
+}
+
+// isInRanges checks whether the value is inside any of the ranges.
+func isInRanges[T Number](value T, ranges []ValidRangeOf[T]) bool {
+	for _, minMax := range ranges {
+		if minMax.Contains(value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Contains checks whether the value matches the configuration: it must be inside one of the
+// ranges, and outside all the exclusions.
+func (c ConfigurationOf[T]) Contains(value T) bool {
+	return isInRanges(value, c.Ranges) && !isInRanges(value, c.Exclusions)
+}
+
+// Validate checks the ticket against the rules. It returns the values matching none of the rules,
+// in the order of the ticket, or nil when the ticket is valid.
+func (t TicketOf[T]) Validate(rules []ConfigurationOf[T]) []T {
+	var invalidValues []T
+
+	for fieldPos, value := range t.Values {
+		if !t.Known(fieldPos) {
+			continue
+		}
+
+		foundValid := false
+
+		for _, config := range rules {
+			// Now we have the value and a config, let's check against it.
+			if config.Contains(value) {
+				// The value is valid
+				foundValid = true
+				break
+			}
+		}
+
+		if !foundValid {
+			invalidValues = append(invalidValues, value)
+		}
+	}
+
+	return invalidValues
+}
+
+// getOrdering gets the ordering of the fields in the ticket from the candidate fields of each position,
+// see candidateMatrix. It repeatedly assigns:
+//
+//   - the positions left with a single candidate field, and
+//   - the fields left with a single candidate position,
+//
+// removing each assigned field from the candidates of the other positions. When no assignment is forced
+// anymore, it falls back to maximum matchings between the positions and the fields left if match is set,
+// picking the fields by the tie-break rule of Algorithm, and gives up with an AmbiguousOrderingError
+// otherwise. Besides the ordering, it tells for each position whether its field was forced.
+//
+// It gives up with the error of the context when the context is done, and with an UnsolvableError when a
+// position or a field runs out of candidates, or when the positions left can't all be given a field. The
+// positions assigned by each round are added to the progress.
+func getOrdering(ctx context.Context, candidates [][]bool, fields []string, match bool, progress *progressCounter) ([]string, []bool, error) {
+	state := newElimination(candidates, len(fields))
+	left := state.left
+
+	// Elimination only ever assigns the last candidate of a position, until the matching picks the rest.
+	forced := make([]bool, len(candidates))
+	for fieldPos := range forced {
+		forced[fieldPos] = true
+	}
+
+	// trace reports the fields placed by the round, which are the last ones assigned.
+	trace := func(number int, matched bool) {
+		if !progress.tracing() {
+			return
+		}
+
+		placed := state.order[len(state.order)-(left-state.left):]
+		names := make([]string, len(placed))
+		for idx, field := range placed {
+			names[idx] = fields[field]
+		}
+		progress.traceRound(Round{Number: number, Fields: names, Matched: matched})
+	}
+
+	for !state.done() {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+		number := progress.round()
+
+		if err := state.unsolvable(fields); err != nil {
+			return nil, nil, err
+		}
+
+		if state.step() {
+			trace(number, false)
+			progress.add(left - state.left)
+			left = state.left
+			continue
+		}
+
+		if !match {
+			// The positions left may not even be given a field each, in which case they aren't ambiguous.
+			if positions := state.clone().match(); positions != nil {
+				return nil, nil, state.unmatchable(fields, positions)
+			}
+
+			return nil, nil, state.ambiguous(fields)
+		}
+
+		picked, positions := state.pick()
+		if positions != nil {
+			return nil, nil, state.unmatchable(fields, positions)
+		}
+		forced = picked
+		trace(number, true)
+		progress.add(left - state.left)
+		left = state.left
+	}
+
+	// More fields than positions leave some fields without a position.
+	if err := state.unsolvable(fields); err != nil {
+		return nil, nil, err
+	}
+
+	ordering := make([]string, len(candidates))
+	for fieldPos, idx := range state.fieldOf {
+		ordering[fieldPos] = fields[idx]
+	}
+
+	return ordering, forced, nil
+}
+
+// elimination keeps the state of the elimination run by getOrdering. The candidates are kept both by
+// position and by field as bitsets, so that counting the candidates of either takes a few word operations
+// instead of a scan of the whole matrix.
+type elimination struct {
+	candidates []bitset // candidates tells, by position, which fields may still be held by the position.
+	holders    []bitset // holders tells, by field, which positions may still hold the field.
+	free       bitset   // free holds the positions not assigned yet.
+	fieldOf    []int    // fieldOf holds the field assigned to each position, -1 when not assigned yet.
+	positionOf []int    // positionOf holds the position assigned to each field, -1 when not assigned yet.
+	left       int      // left counts the positions not assigned yet.
+	order      []int    // order lists the fields in the order they were assigned, for the round trace.
+}
+
+// newElimination starts an elimination over the candidates.
+func newElimination(candidates [][]bool, fields int) *elimination {
+	state := &elimination{
+		candidates: make([]bitset, len(candidates)),
+		holders:    make([]bitset, fields),
+		free:       newBitset(len(candidates)),
+		fieldOf:    make([]int, len(candidates)),
+		positionOf: make([]int, fields),
+		left:       len(candidates),
+	}
+
+	for idx := range state.holders {
+		state.holders[idx] = newBitset(len(candidates))
+		state.positionOf[idx] = -1
+	}
+
+	for fieldPos, row := range candidates {
+		state.candidates[fieldPos] = newBitset(fields)
+		state.fieldOf[fieldPos] = -1
+		state.free.set(fieldPos)
+
+		for idx, candidate := range row {
+			if candidate {
+				state.candidates[fieldPos].set(idx)
+				state.holders[idx].set(fieldPos)
+			}
+		}
+	}
+
+	return state
+}
+
+// done tells whether all the positions are assigned.
+func (e *elimination) done() bool {
+	return e.left == 0
+}
+
+// assign assigns the field to the position, and removes it from the candidates of the other positions.
+func (e *elimination) assign(fieldPos int, idx int) {
+	e.fieldOf[fieldPos] = idx
+	e.positionOf[idx] = fieldPos
+	e.free.clear(fieldPos)
+	e.left--
+	e.order = append(e.order, idx)
+
+	holders := e.holders[idx]
+	for other := holders.next(0); other >= 0; other = holders.next(other + 1) {
+		e.candidates[other].clear(idx)
+	}
+
+	holders.reset()
+	holders.set(fieldPos)
+	e.candidates[fieldPos].set(idx)
+}
+
+// step runs one pass of assignments over the positions, then over the fields. It tells whether anything
+// was assigned.
+func (e *elimination) step() bool {
+	progressed := false
+
+	// A position with a single candidate holds that field.
+	for fieldPos := e.free.next(0); fieldPos >= 0; fieldPos = e.free.next(fieldPos + 1) {
+		if row := e.candidates[fieldPos]; row.count() == 1 {
+			e.assign(fieldPos, row.next(0))
+			progressed = true
+		}
+	}
+
+	// A field with a single candidate position is held by that position.
+	for idx, assignedPos := range e.positionOf {
+		if assignedPos >= 0 {
+			continue
+		}
+
+		// The positions assigned already keep their other candidates, they don't count.
+		if holders := e.holders[idx]; holders.countAnd(e.free) == 1 {
+			e.assign(holders.nextAnd(e.free, 0), idx)
+			progressed = true
+		}
+	}
+
+	return progressed
+}
+
+// unsolvable returns an UnsolvableError when a position left has no candidate field, or when a field left
+// has no candidate position. It returns nil otherwise.
+func (e *elimination) unsolvable(fields []string) error {
+	err := &UnsolvableError{}
+
+	for fieldPos := e.free.next(0); fieldPos >= 0; fieldPos = e.free.next(fieldPos + 1) {
+		if e.candidates[fieldPos].count() == 0 {
+			err.Positions = append(err.Positions, fieldPos)
+		}
+	}
+
+	if len(err.Positions) > 0 {
+		for idx, assignedPos := range e.positionOf {
+			if assignedPos < 0 {
+				err.Fields = append(err.Fields, fields[idx])
+			}
+		}
+
+		return err
+	}
+
+	for idx, assignedPos := range e.positionOf {
+		if assignedPos < 0 && e.holders[idx].nextAnd(e.free, 0) < 0 {
+			err.Fields = append(err.Fields, fields[idx])
+		}
+	}
+
+	if len(err.Fields) > 0 {
+		return err
+	}
+
+	return nil
+}
+
+// deadEnd tells whether a position left has no candidate field anymore.
+func (e *elimination) deadEnd() bool {
+	for fieldPos := e.free.next(0); fieldPos >= 0; fieldPos = e.free.next(fieldPos + 1) {
+		if e.candidates[fieldPos].next(0) < 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// unmatchable returns the UnsolvableError describing the positions left without a field by the matching,
+// along with the fields left.
+func (e *elimination) unmatchable(fields []string, positions []int) error {
+	err := &UnsolvableError{Positions: positions}
+
+	for idx, assignedPos := range e.positionOf {
+		if assignedPos < 0 {
+			err.Fields = append(err.Fields, fields[idx])
+		}
+	}
+
+	return err
+}
+
+// ambiguous returns the AmbiguousOrderingError describing the positions left, when no assignment is
+// forced anymore.
+func (e *elimination) ambiguous(fields []string) error {
+	err := &AmbiguousOrderingError{}
+
+	for fieldPos := e.free.next(0); fieldPos >= 0; fieldPos = e.free.next(fieldPos + 1) {
+		candidates := make([]string, 0)
+
+		row := e.candidates[fieldPos]
+		for idx := row.next(0); idx >= 0; idx = row.next(idx + 1) {
+			candidates = append(candidates, fields[idx])
+		}
+
+		err.Positions = append(err.Positions, fieldPos)
+		err.Candidates = append(err.Candidates, candidates)
+	}
+
+	return err
+}
+
+// DeparturePrefix is the prefix of the fields whose values are multiplied together in part 2.
+const DeparturePrefix = "departure "
+
+// Result holds the answers to both parts of the puzzle.
+type Result struct {
+	// Part1 is the ticket scanning error rate: the sum of the values of the nearby tickets that match no rule.
+	Part1 int64
+
+	// Part2 is the product of the values of your ticket whose field starts with DeparturePrefix, or their
+	// other Aggregation, see WithAggregation. Part2Values lists these values, in the order of the ticket.
+	Part2       int64
+	Part2Values []int64
+	Aggregation Aggregation
+
+	// Part2Unknown lists the fields of part 2 whose value is unknown in your ticket, in the order of the
+	// ticket. They are left out of Part2, which can't be trusted when it isn't empty.
+	Part2Unknown []string
+
+	// Part1Overflow tells that the sum of Part1 overflows int64, leaving Part1 wrapped around. Part1Big holds
+	// the right answer, when computed.
+	Part1Overflow bool
+
+	// Part2Overflow tells that the sum or the product of Part2 overflows int64, leaving Part2 wrapped
+	// around. Part2Big holds the right answer, when computed.
+	Part2Overflow bool
+
+	// Part1Big and Part2Big are Part1 and Part2 computed with arbitrary precision, when the solver was
+	// asked to with WithBigInt. They are nil otherwise.
+	Part1Big *big.Int
+	Part2Big *big.Int
+
+	// Ordering holds the field found at each position of the tickets.
+	Ordering []string
+
+	// Groups holds the groups of fields asked with WithGroups, in the order they were given.
+	Groups []Group
+
+	// Violations lists the values of the tickets kept to infer the ordering that the rule of their position
+	// doesn't allow. It is empty unless your ticket was left out of the inference, or the inference is wrong.
+	Violations []Violation
+
+	// Positions tells how trustworthy the field found at each position is.
+	Positions []PositionReport
+
+	// MyTicketInvalid lists the values of your ticket matching no rule, when the solver checks it and
+	// only warns, see MyTicketCheckWarn. Part 2 can't be trusted when it isn't empty.
+	MyTicketInvalid []InvalidValue
+
+	// InvalidDetails describes the invalid and tolerated nearby tickets, in the order of the notes, when the
+	// solver was asked to with WithInvalidDetails. It is nil otherwise.
+	InvalidDetails []InvalidTicket
+
+	// ValidTickets and InvalidTickets count the nearby tickets, your ticket aside. ToleratedTickets counts
+	// the ones holding a few invalid values that were still used, see WithMaxInvalidValues.
+	ValidTickets     int
+	InvalidTickets   int
+	ToleratedTickets int
+
+	// RaggedTickets counts the nearby tickets left out because their number of values differs from your
+	// ticket, see RaggedSkip. The ones skipped while parsing the text format are counted in Notes.Skipped.
+	RaggedTickets int
+
+	// Duplicates tells how many of the tickets the ordering was inferred from were identical, when the
+	// solver was asked to collapse them with WithDedup. It is nil otherwise.
+	Duplicates *DuplicateStats
+
+	// Metrics breaks Part1 down, when the solver was asked to with WithMetrics. It is nil otherwise.
+	Metrics *ErrorMetrics
+
+	// Timings tells how long each step took.
+	Timings Timings
+
+	// Rounds counts the rounds of the elimination that inferred the ordering, added up over the components
+	// of the wide notes. The backtracking runs none.
+	Rounds int
+}
+
+// Group gathers the fields of your ticket starting with a prefix, like the departure fields of part 2.
+type Group struct {
+	Prefix string   // Prefix is the prefix of the fields of the group.
+	Fields []string // Fields lists the fields of the group, in the order of the ticket.
+	Values []int64  // Values lists the values of your ticket for the Fields.
+
+	// Value is the product of the Values, 1 when the group is empty, or their other Aggregation. BigValue
+	// is the same with arbitrary precision, when the solver was asked to with WithBigInt.
+	Value    int64
+	BigValue *big.Int
+
+	// Overflow tells that the Value overflows int64, like Result.Part2Overflow.
+	Overflow bool
+
+	// Unknown lists the fields of the group whose value is unknown in your ticket, which are left out of
+	// the Fields and the Values.
+	Unknown []string
+}
+
+// PositionReport tells how the field of a position of the tickets was found.
+type PositionReport struct {
+	Position int    // Position is the 0-based position in the tickets.
+	Field    string // Field is the field found at the position.
+
+	// Candidates lists the fields matching the values of all the valid tickets at the position, before
+	// any elimination, in the order of the rules.
+	Candidates []string
+
+	// Forced tells that the field is the only one the position holds in all the orderings consistent with
+	// the tickets. Otherwise it was picked among several by the tie-break rule of Algorithm, and another
+	// ordering is as consistent with the tickets.
+	Forced bool
+}
+
+// Part1Answer formats the answer to part 1, with arbitrary precision when it was computed.
+func (r Result) Part1Answer() string {
+	if r.Part1Big != nil {
+		return r.Part1Big.String()
+	}
+
+	return strconv.FormatInt(r.Part1, 10)
+}
+
+// Part2Answer formats the answer to part 2 according to its Aggregation, with arbitrary precision when it
+// was computed.
+func (r Result) Part2Answer() string {
+	if r.Part2Big != nil && r.Aggregation != AggregateList {
+		return r.Part2Big.String()
+	}
+
+	return r.Aggregation.Answer(r.Part2, r.Part2Values)
+}
+
+// GroupAnswer formats the value of the group like Part2Answer.
+func (r Result) GroupAnswer(group Group) string {
+	if group.BigValue != nil && r.Aggregation != AggregateList {
+		return group.BigValue.String()
+	}
+
+	return r.Aggregation.Answer(group.Value, group.Values)
+}
+
+// Certain tells whether the field of every position was forced, so that the ordering is the only one
+// consistent with the tickets.
+func (r Result) Certain() bool {
+	for _, position := range r.Positions {
+		if !position.Forced {
+			return false
+		}
+	}
+
+	return true
+}
+
+// positionReports describes how the field of each position was found from the candidates.
+func positionReports(matrix [][]bool, fields []string, ordering []string, forced []bool) []PositionReport {
+	reports := make([]PositionReport, len(ordering))
+	for fieldPos, field := range ordering {
+		candidates := make([]string, 0)
+		for idx, name := range fields {
+			if matrix[fieldPos][idx] {
+				candidates = append(candidates, name)
+			}
+		}
+
+		reports[fieldPos] = PositionReport{Position: fieldPos, Field: field, Candidates: candidates, Forced: forced[fieldPos]}
+	}
+
+	return reports
+}
+
+// candidateMatrix tells, for each of the positions of the tickets and each rule, whether the rule matches
+// the values of all the tickets at the position. The unknown values match every rule. The positions are
+// independent, so up to workers of them are checked at the same time, each filling its own row.
+func candidateMatrix[T Number](positions int, tickets []TicketOf[T], rules []RuleOf[T], workers int) [][]bool {
+	columns := transpose(positions, tickets)
+	tables := denseRules(rules)
+
+	matrix := make([][]bool, positions)
+	if workers <= 1 || positions <= 1 {
+		for fieldPos, column := range columns {
+			matrix[fieldPos] = columnCandidates(column, rules, tables)
+		}
+
+		return matrix
+	}
+
+	semaphore := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+	for fieldPos, column := range columns {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(fieldPos int, column []T) {
+			defer wg.Done()
+			matrix[fieldPos] = columnCandidates(column, rules, tables)
+			<-semaphore
+		}(fieldPos, column)
+	}
+	wg.Wait()
+
+	return matrix
+}
+
+// transpose lays the known values of the tickets out by position, once, so that each rule reads the
+// values of a position from a single slice instead of going through every ticket again.
+func transpose[T Number](positions int, tickets []TicketOf[T]) [][]T {
+	columns, _ := transposeInto(positions, tickets, nil, nil)
+	return columns
+}
+
+// transposeInto is like transpose, but reuses the given columns and backing array when they are large
+// enough. It returns the backing array to reuse next time.
+func transposeInto[T Number](positions int, tickets []TicketOf[T], columns [][]T, backing []T) ([][]T, []T) {
+	if cap(columns) < positions {
+		columns = make([][]T, positions)
+	}
+	columns = columns[:positions]
+
+	if cap(backing) < positions*len(tickets) {
+		backing = make([]T, 0, positions*len(tickets))
+	}
+	backing = backing[:0]
+
+	for fieldPos := range columns {
+		start := len(backing)
+		for _, ticket := range tickets {
+			if ticket.Known(fieldPos) {
+				backing = append(backing, ticket.Values[fieldPos])
+			}
+		}
+
+		columns[fieldPos] = backing[start:len(backing):len(backing)]
+	}
+
+	return columns, backing
+}
+
+// columnCandidates tells, for each rule, whether it matches all the values of a position. The rules are
+// checked with their lookup table when they have one, see denseRules.
+func columnCandidates[T Number](column []T, rules []RuleOf[T], tables []*denseTable[T]) []bool {
+	candidates := make([]bool, len(rules))
+
+	for idx, rule := range rules {
+		matches := true
+		for _, value := range column {
+			if !matchesDense(rule, tables[idx], value) {
+				matches = false
+				break
+			}
+		}
+
+		candidates[idx] = matches
+	}
+
+	return candidates
+}
+
+// narrowCandidates narrows the candidates with tickets holding a few invalid values. The positions of
+// these values are left out, so that each ticket only narrows the candidates of its other positions.
+func narrowCandidates(matrix [][]bool, tickets []InvalidTicket, rules []Rule) {
+	for _, invalid := range tickets {
+		narrowTicket(matrix, invalid.Ticket, invalid.Values, rules)
+	}
+}
+
+// narrowTicket rules out the candidates of each position that the value of the ticket doesn't match,
+// leaving out the positions of the invalid values and the unknown values.
+func narrowTicket(matrix [][]bool, ticket Ticket, invalids []InvalidValue, rules []Rule) {
+	for fieldPos, value := range ticket.Values {
+		if !ticket.Known(fieldPos) || invalidAt(invalids, fieldPos) {
+			continue
+		}
+
+		for idx, rule := range rules {
+			if matrix[fieldPos][idx] && !rule.Matches(value) {
+				matrix[fieldPos][idx] = false
+			}
+		}
+	}
+}
+
+// invalidAt checks whether one of the invalid values is at the position. A ticket only holds a few of
+// them, so they are simply scanned.
+func invalidAt(invalids []InvalidValue, fieldPos int) bool {
+	for _, invalid := range invalids {
+		if invalid.Position == fieldPos {
+			return true
+		}
+	}
+
+	return false
+}
+
+// allCandidates returns the candidates of positions before any ticket is seen: every rule at every
+// position.
+func allCandidates(positions int, rules int) [][]bool {
+	matrix := make([][]bool, positions)
+	for fieldPos := range matrix {
+		matrix[fieldPos] = make([]bool, rules)
+		for idx := range matrix[fieldPos] {
+			matrix[fieldPos][idx] = true
+		}
+	}
+
+	return matrix
+}
+
+// ruleNames returns the names of the rules, which are the fields of the ordering.
+func ruleNames[T Number](rules []RuleOf[T]) []string {
+	fields := make([]string, len(rules))
+	for idx, rule := range rules {
+		fields[idx] = rule.Name()
+	}
+
+	return fields
+}
+
+// Timings holds the time spent in each step of a solve. The steps that were not run are zero.
+type Timings struct {
+	Parse    time.Duration // Parse is only measured when the solver reads the notes itself.
+	Validate time.Duration
+	Order    time.Duration
+}
+
+// Total returns the time spent in all the steps.
+func (t Timings) Total() time.Duration {
+	return t.Parse + t.Validate + t.Order
+}
+
+// Solve reads the notes in the text format of the puzzle and solves both parts with the default solver.
+func Solve(r io.Reader) (Result, error) {
+	return NewSolver().Solve(r)
+}
+
+// SolveContext is like Solve, but gives up with the error of the context when the context is done.
+func SolveContext(ctx context.Context, r io.Reader) (Result, error) {
+	return NewSolver().SolveContext(ctx, r)
+}
+
+// SolveNotes solves both parts of the puzzle for notes that were already read, with the default solver.
+func SolveNotes(notes *Notes) (Result, error) {
+	return NewSolver().SolveNotes(notes)
+}
+
+// SolveNotesContext is like SolveNotes, but gives up with the error of the context when the context is done.
+func SolveNotesContext(ctx context.Context, notes *Notes) (Result, error) {
+	return NewSolver().SolveNotesContext(ctx, notes)
+}
@@ -0,0 +1,927 @@
+package ticket16
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Algorithm identifies the way the ordering of the fields is inferred.
+//
+// The algorithms don't depend on anything but the order of the positions and the order of the rules, so
+// they always agree. When several orderings are consistent with the tickets, the ones able to pick follow
+// the same tie-break rule: from the first position to the last, each position takes the first field, in
+// the order of the rules, that still lets the positions after it all be given a different field. This is
+// the first ordering listed by Solver.Orderings.
+type Algorithm string
+
+const (
+	// AlgorithmMatching runs the elimination, then falls back to a maximum bipartite matching between the
+	// positions and the fields left when no assignment is forced anymore. It is the default algorithm.
+	AlgorithmMatching Algorithm = "matching"
+	// AlgorithmElimination repeatedly assigns the positions matching a single remaining field, and fails
+	// with an AmbiguousOrderingError when no assignment is forced anymore.
+	AlgorithmElimination Algorithm = "elimination"
+	// AlgorithmBacktrack searches the orderings depth-first. It always finds an ordering when there is one,
+	// but may take exponential time, so it is mostly useful to check the other algorithms.
+	AlgorithmBacktrack Algorithm = "backtrack"
+)
+
+// ParseAlgorithm checks the name of an ordering algorithm, as written on the command line.
+func ParseAlgorithm(name string) (Algorithm, error) {
+	switch algorithm := Algorithm(name); algorithm {
+	case AlgorithmMatching, AlgorithmElimination, AlgorithmBacktrack:
+		return algorithm, nil
+	default:
+		return "", fmt.Errorf("unknown ordering algorithm %q", name)
+	}
+}
+
+// MyTicketPolicy tells whether your ticket takes part in the inference of the ordering.
+type MyTicketPolicy string
+
+const (
+	// MyTicketInclude infers the ordering from your ticket too, assuming it is valid. It is the default policy.
+	MyTicketInclude MyTicketPolicy = "include"
+	// MyTicketExclude infers the ordering from the nearby tickets only.
+	MyTicketExclude MyTicketPolicy = "exclude"
+	// MyTicketValidate checks your ticket like a nearby ticket first, and only includes it when it is valid.
+	MyTicketValidate MyTicketPolicy = "validate"
+)
+
+// ParseMyTicketPolicy checks the name of a policy for your ticket, as written on the command line.
+func ParseMyTicketPolicy(name string) (MyTicketPolicy, error) {
+	switch policy := MyTicketPolicy(name); policy {
+	case MyTicketInclude, MyTicketExclude, MyTicketValidate:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unknown policy %q for your ticket", name)
+	}
+}
+
+// Aggregation tells how the values of the fields of part 2 and of the groups are combined.
+type Aggregation string
+
+const (
+	// AggregateProduct multiplies the values together, like the puzzle. It is the default aggregation.
+	AggregateProduct Aggregation = "product"
+	// AggregateSum adds the values together.
+	AggregateSum Aggregation = "sum"
+	// AggregateMin keeps the lowest value.
+	AggregateMin Aggregation = "min"
+	// AggregateMax keeps the highest value.
+	AggregateMax Aggregation = "max"
+	// AggregateList keeps all the values, leaving the aggregated value to 0.
+	AggregateList Aggregation = "list"
+)
+
+// ParseAggregation checks the name of an aggregation, as written on the command line.
+func ParseAggregation(name string) (Aggregation, error) {
+	switch aggregation := Aggregation(name); aggregation {
+	case AggregateProduct, AggregateSum, AggregateMin, AggregateMax, AggregateList:
+		return aggregation, nil
+	default:
+		return "", fmt.Errorf("unknown aggregation %q", name)
+	}
+}
+
+// aggregate combines the values, and tells whether the sum or the product overflows int64, leaving the
+// value wrapped around. The product of no value is 1, and the other aggregations of no value are 0.
+func (a Aggregation) aggregate(values []int64) (int64, bool) {
+	switch a {
+	case AggregateSum:
+		sum, overflow := int64(0), false
+		for _, value := range values {
+			var wrapped bool
+			sum, wrapped = addInt64(sum, value)
+			overflow = overflow || wrapped
+		}
+
+		return sum, overflow
+	case AggregateMin, AggregateMax:
+		if len(values) == 0 {
+			return 0, false
+		}
+
+		best := values[0]
+		for _, value := range values[1:] {
+			if a == AggregateMin && value < best || a == AggregateMax && value > best {
+				best = value
+			}
+		}
+
+		return best, false
+	case AggregateList:
+		return 0, false
+	default:
+		product, overflow := int64(1), false
+		for _, value := range values {
+			next := product * value
+			// Dividing back gives the other factor unless the product wrapped around, which -1 times the
+			// lowest integer does without changing the quotient.
+			overflow = overflow || value != 0 && (next/value != product || value == -1 && product == math.MinInt64)
+			product = next
+		}
+
+		return product, overflow
+	}
+}
+
+// addInt64 adds the value to the sum, and tells whether the sum wraps around.
+func addInt64(sum, value int64) (int64, bool) {
+	next := sum + value
+	// The sum moves the other way than the value only when it wraps around.
+	return next, (next > sum) != (value > 0)
+}
+
+// aggregateBig is like aggregate, with arbitrary precision. The values can't overflow when kept as they are.
+func (a Aggregation) aggregateBig(values []int64) *big.Int {
+	switch a {
+	case AggregateSum:
+		sum := new(big.Int)
+		for _, value := range values {
+			sum.Add(sum, big.NewInt(value))
+		}
+
+		return sum
+	case AggregateProduct, "":
+		product := big.NewInt(1)
+		for _, value := range values {
+			product.Mul(product, big.NewInt(value))
+		}
+
+		return product
+	default:
+		value, _ := a.aggregate(values)
+		return big.NewInt(value)
+	}
+}
+
+// Answer formats an aggregated value: the value itself, or the values separated by commas for AggregateList.
+func (a Aggregation) Answer(value int64, values []int64) string {
+	if a != AggregateList {
+		return strconv.FormatInt(value, 10)
+	}
+
+	formatted := make([]string, len(values))
+	for idx, value := range values {
+		formatted[idx] = strconv.FormatInt(value, 10)
+	}
+
+	return strings.Join(formatted, ",")
+}
+
+// MyTicketCheck tells what to do when your ticket holds values matching none of the rules.
+type MyTicketCheck string
+
+const (
+	// MyTicketCheckWarn reports the invalid values of your ticket in Result.MyTicketInvalid. It is the default.
+	MyTicketCheckWarn MyTicketCheck = "warn"
+	// MyTicketCheckError fails the solve with an InvalidMyTicketError.
+	MyTicketCheckError MyTicketCheck = "error"
+	// MyTicketCheckOff doesn't check your ticket.
+	MyTicketCheckOff MyTicketCheck = "off"
+)
+
+// InvalidCollection tells which invalid values of a nearby ticket the validation collects.
+type InvalidCollection string
+
+const (
+	// CollectAll collects every invalid value of the tickets, which part 1 needs. It is the default.
+	CollectAll InvalidCollection = "all"
+	// CollectFirst stops at the first invalid value of each ticket, which is faster when only the
+	// validity of the tickets matters. Validation.ErrorRate then only sums these values.
+	CollectFirst InvalidCollection = "first"
+)
+
+// ParseInvalidCollection checks the name of a way to collect the invalid values.
+func ParseInvalidCollection(name string) (InvalidCollection, error) {
+	switch collection := InvalidCollection(name); collection {
+	case CollectAll, CollectFirst:
+		return collection, nil
+	default:
+		return "", fmt.Errorf("unknown collection %q of the invalid values", name)
+	}
+}
+
+// ParseMyTicketCheck checks the name of a check of your ticket, as written on the command line.
+func ParseMyTicketCheck(name string) (MyTicketCheck, error) {
+	switch check := MyTicketCheck(name); check {
+	case MyTicketCheckWarn, MyTicketCheckError, MyTicketCheckOff:
+		return check, nil
+	default:
+		return "", fmt.Errorf("unknown check %q for your ticket", name)
+	}
+}
+
+// Solver solves the puzzle. Its behaviour is tuned by the options given to NewSolver.
+//
+// A Solver is safe for concurrent use by multiple goroutines. Its options can't change once it is
+// created, and the state of the incremental solve is guarded by a lock, so that a server may keep
+// adding tickets while answering many CurrentOrdering and Decode queries in parallel.
+type Solver struct {
+	parse           ParseOptions
+	departurePrefix string
+	groups          []string
+	aggregation     Aggregation
+	bigInt          bool
+	metrics         bool
+	invalidDetails  bool
+	dedup           bool
+	parallelism     int
+	chunkSize       int
+	algorithm       Algorithm
+	myTicket        MyTicketPolicy
+	myTicketCheck   MyTicketCheck
+	maxInvalid      int
+	collection      InvalidCollection
+	progress        func(progress Progress)
+	roundTrace      func(round Round)
+	rules           []Rule
+
+	mu          sync.RWMutex
+	incremental *incrementalState // incremental is the state of the solve started by SetRules.
+}
+
+// Option tunes a Solver created by NewSolver.
+type Option func(*Solver)
+
+// WithParseOptions sets how the notes are read by Solver.Parse.
+func WithParseOptions(opts ParseOptions) Option {
+	return func(s *Solver) {
+		s.parse = opts
+	}
+}
+
+// WithStrict turns every structural problem of the notes into an error, see ParseOptions.Strict.
+func WithStrict(strict bool) Option {
+	return func(s *Solver) {
+		s.parse.Strict = strict
+	}
+}
+
+// WithDeparturePrefix sets the prefix of the fields multiplied together in part 2. It defaults to DeparturePrefix.
+func WithDeparturePrefix(prefix string) Option {
+	return func(s *Solver) {
+		s.departurePrefix = prefix
+	}
+}
+
+// WithGroups adds groups of fields to report in Result.Groups besides part 2: each group gathers the
+// fields starting with its prefix, e.g. "arrival ".
+func WithGroups(prefixes ...string) Option {
+	return func(s *Solver) {
+		s.groups = append(s.groups, prefixes...)
+	}
+}
+
+// WithAggregation sets how the values of the fields of part 2 and of the groups are combined. It defaults
+// to AggregateProduct, as asked by the puzzle.
+func WithAggregation(aggregation Aggregation) Option {
+	return func(s *Solver) {
+		s.aggregation = aggregation
+	}
+}
+
+// WithBigInt computes the answers with arbitrary precision as well, in Result.Part1Big, Result.Part2Big
+// and Group.BigValue, for the notes whose sums and products overflow int64.
+func WithBigInt(bigInt bool) Option {
+	return func(s *Solver) {
+		s.bigInt = bigInt
+	}
+}
+
+// WithMetrics breaks the error rate down in Result.Metrics: the tickets discarded, the values each rule
+// accepts and the positions holding the invalid values.
+func WithMetrics(metrics bool) Option {
+	return func(s *Solver) {
+		s.metrics = metrics
+	}
+}
+
+// WithInvalidDetails lists the invalid and tolerated nearby tickets in Result.InvalidDetails, with their
+// values matching no rule. SolveStream doesn't list them, since it doesn't keep the tickets.
+func WithInvalidDetails(details bool) Option {
+	return func(s *Solver) {
+		s.invalidDetails = details
+	}
+}
+
+// WithDedup collapses the identical tickets before inferring the ordering, which can't change it, and
+// reports how many there were in Result.Duplicates. It saves time on large generated notes.
+func WithDedup(dedup bool) Option {
+	return func(s *Solver) {
+		s.dedup = dedup
+	}
+}
+
+// WithChunkSize sets the number of nearby tickets SolveStream keeps before folding them into the answers.
+// It defaults to DefaultChunkSize; larger chunks trade memory for fewer passes over the candidates.
+func WithChunkSize(size int) Option {
+	return func(s *Solver) {
+		s.chunkSize = size
+	}
+}
+
+// WithParallelism sets the number of goroutines validating the nearby tickets and checking the rules
+// against the positions. It defaults to 0, which uses GOMAXPROCS goroutines. Small notes are validated by
+// fewer goroutines, each taking at least minValidationChunk tickets.
+func WithParallelism(parallelism int) Option {
+	return func(s *Solver) {
+		s.parallelism = parallelism
+	}
+}
+
+// workers returns the number of goroutines set by WithParallelism, resolving 0 to GOMAXPROCS.
+func (s *Solver) workers() int {
+	if s.parallelism < 1 {
+		return runtime.GOMAXPROCS(0)
+	}
+
+	return s.parallelism
+}
+
+// WithAlgorithm sets the way the ordering of the fields is inferred. It defaults to AlgorithmMatching.
+func WithAlgorithm(algorithm Algorithm) Option {
+	return func(s *Solver) {
+		s.algorithm = algorithm
+	}
+}
+
+// WithMyTicket sets whether your ticket takes part in the inference of the ordering. It defaults to
+// MyTicketInclude, since some rules can only be told apart thanks to it, while others can only be told
+// apart without it.
+func WithMyTicket(policy MyTicketPolicy) Option {
+	return func(s *Solver) {
+		s.myTicket = policy
+	}
+}
+
+// WithMyTicketCheck sets what to do when your ticket holds values matching none of the rules. It defaults
+// to MyTicketCheckWarn.
+func WithMyTicketCheck(check MyTicketCheck) Option {
+	return func(s *Solver) {
+		s.myTicketCheck = check
+	}
+}
+
+// WithMaxInvalidValues tolerates the nearby tickets holding up to max invalid values: instead of being
+// discarded, they take part in the inference of the ordering, only leaving out the positions of their
+// invalid values. It defaults to 0, which discards every ticket holding an invalid value like the puzzle.
+// The invalid values of the tolerated tickets still count in the error rate.
+func WithMaxInvalidValues(max int) Option {
+	return func(s *Solver) {
+		s.maxInvalid = max
+	}
+}
+
+// WithInvalidCollection sets which invalid values of the nearby tickets Validate collects. It defaults to
+// CollectAll. SolveNotes always collects them all, since part 1 sums them, and so does Validate when the
+// solver tolerates a few invalid values, see WithMaxInvalidValues, since they have to be counted.
+func WithInvalidCollection(collection InvalidCollection) Option {
+	return func(s *Solver) {
+		s.collection = collection
+	}
+}
+
+// WithRules adds custom rules to the rules of the notes. They describe fields like the configurations
+// do, and take part in the validation and in the inference of the ordering alongside them.
+func WithRules(rules ...Rule) Option {
+	return func(s *Solver) {
+		s.rules = append(s.rules, rules...)
+	}
+}
+
+// NewSolver creates a solver tuned by the given options.
+func NewSolver(opts ...Option) *Solver {
+	s := &Solver{
+		departurePrefix: DeparturePrefix,
+		aggregation:     AggregateProduct,
+		algorithm:       AlgorithmMatching,
+		myTicket:        MyTicketInclude,
+		myTicketCheck:   MyTicketCheckWarn,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// Validation tells which nearby tickets are valid.
+type Validation struct {
+	Valid   []Ticket        // Valid lists the nearby tickets whose values all match a rule, in the order of the notes.
+	Invalid []InvalidTicket // Invalid describes the other nearby tickets, in the order of the notes.
+
+	// Tolerated describes the nearby tickets holding a few invalid values, in the order of the notes, when
+	// the solver tolerates them, see WithMaxInvalidValues. They are not listed in Invalid.
+	Tolerated []InvalidTicket
+
+	// Ragged lists the indexes of the nearby tickets left out because their number of values differs from
+	// your ticket, see RaggedSkip.
+	Ragged []int
+
+	// ErrorRate is the sum of the values matching no rule, which is the answer to part 1. It only sums the
+	// first invalid value of each ticket with CollectFirst.
+	ErrorRate int64
+
+	// ErrorRateOverflow tells that the sum of ErrorRate overflows int64, leaving it wrapped around.
+	ErrorRateOverflow bool
+}
+
+// Parse reads the notes in the text format of the puzzle.
+func (s *Solver) Parse(r io.Reader) (*Notes, error) {
+	return ParseNotes(r, s.parse)
+}
+
+// Validate checks the nearby tickets of the notes against their rules.
+func (s *Solver) Validate(notes *Notes) Validation {
+	validation, _ := s.ValidateContext(context.Background(), notes)
+	return validation
+}
+
+// ValidateContext is like Validate, but gives up with the error of the context when the context is done.
+func (s *Solver) ValidateContext(ctx context.Context, notes *Notes) (Validation, error) {
+	return s.validate(ctx, notes, s.collection)
+}
+
+// validate checks the nearby tickets of the notes against their rules, collecting their invalid values as
+// asked. All of them are collected when the solver tolerates a few, to count them.
+func (s *Solver) validate(ctx context.Context, notes *Notes, collection InvalidCollection) (Validation, error) {
+	switch collection {
+	case CollectAll, "":
+		collection = CollectAll
+	case CollectFirst:
+		if s.maxInvalid > 0 {
+			collection = CollectAll
+		}
+	default:
+		return Validation{}, fmt.Errorf("unknown collection %q of the invalid values", collection)
+	}
+
+	tickets := notes.NearbyTickets
+	configs := compileRules(notes.Configs, s.rules)
+	index := newValidIndex(configs)
+
+	// The notes read from other formats than text weren't checked for ragged tickets yet.
+	width := len(notes.MyTicket.Values)
+	if width == 0 && len(tickets) > 0 {
+		width = len(tickets[0].Values)
+	}
+
+	ragged := raggedTickets(tickets, width)
+	if len(ragged) > 0 && s.parse.Ragged != RaggedSkip {
+		return Validation{}, raggedError(ragged, width)
+	}
+
+	progress := s.newProgress(ProgressValidate, len(tickets))
+
+	workers := s.workers()
+	if max := (len(tickets) + minValidationChunk - 1) / minValidationChunk; workers > max {
+		workers = max
+	}
+	if workers <= 1 {
+		validation, err := validateTickets(ctx, tickets, 0, width, configs, index, s.maxInvalid, collection, progress)
+		validation.Ragged = ragged
+		return validation, err
+	}
+
+	// Each worker validates a contiguous chunk, so that merging the chunks keeps the order of the notes.
+	chunks := make([]Validation, workers)
+	errs := make([]error, workers)
+	chunkSize := (len(tickets) + workers - 1) / workers
+
+	var wg sync.WaitGroup
+	for idx := range chunks {
+		start, end := idx*chunkSize, (idx+1)*chunkSize
+		if end > len(tickets) {
+			end = len(tickets)
+		}
+
+		wg.Add(1)
+		go func(idx int, start int, chunk []Ticket) {
+			defer wg.Done()
+			chunks[idx], errs[idx] = validateTickets(ctx, chunk, start, width, configs, index, s.maxInvalid, collection, progress)
+		}(idx, start, tickets[start:end])
+	}
+	wg.Wait()
+
+	// All the workers stop with the same error when the context is done.
+	if err := ctx.Err(); err != nil {
+		return Validation{}, err
+	}
+
+	// Size the merged lists once, since they can hold millions of tickets.
+	valid, invalid, tolerated := 0, 0, 0
+	for _, chunk := range chunks {
+		valid += len(chunk.Valid)
+		invalid += len(chunk.Invalid)
+		tolerated += len(chunk.Tolerated)
+	}
+
+	validation := Validation{Ragged: ragged}
+	if valid > 0 {
+		validation.Valid = make([]Ticket, 0, valid)
+	}
+	if invalid > 0 {
+		validation.Invalid = make([]InvalidTicket, 0, invalid)
+	}
+	if tolerated > 0 {
+		validation.Tolerated = make([]InvalidTicket, 0, tolerated)
+	}
+	for _, chunk := range chunks {
+		validation.Valid = append(validation.Valid, chunk.Valid...)
+		validation.Invalid = append(validation.Invalid, chunk.Invalid...)
+		validation.Tolerated = append(validation.Tolerated, chunk.Tolerated...)
+		validation.addErrorRate(chunk.ErrorRate)
+		validation.ErrorRateOverflow = validation.ErrorRateOverflow || chunk.ErrorRateOverflow
+	}
+
+	return validation, nil
+}
+
+// minValidationChunk is the smallest number of tickets worth validating in a goroutine of their own.
+const minValidationChunk = 4096
+
+// contextCheckInterval is the number of tickets validated between two checks of the context.
+const contextCheckInterval = 1024
+
+// validateTickets checks the tickets against the rules, tolerating the tickets holding up to maxInvalid
+// invalid values, and leaving out the tickets that don't have width values. The first ticket is the one
+// at the given index of the nearby tickets. The index of the rules tells the valid tickets apart, so that
+// the rules are only tried one by one to explain the invalid values, all of them or the first one of each
+// ticket depending on the collection. The progress is reported along the checks of the context.
+func validateTickets(ctx context.Context, tickets []Ticket, first int, width int, configs []RuleOf[int64], index validIndex[int64], maxInvalid int, collection InvalidCollection, progress *progressCounter) (Validation, error) {
+	validation := Validation{}
+
+	reported := 0
+	for idx, ticket := range tickets {
+		if idx%contextCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return Validation{}, err
+			}
+
+			if idx > 0 {
+				progress.add(idx - reported)
+				reported = idx
+			}
+		}
+
+		if len(ticket.Values) != width {
+			continue
+		}
+
+		if index.validTicket(ticket) {
+			validation.Valid = append(validation.Valid, ticket)
+			continue
+		}
+
+		invalids := explainTicket(ticket, configs, index, collection)
+		invalid := InvalidTicket{Index: first + idx, Ticket: ticket, Values: invalids}
+		if len(invalids) <= maxInvalid {
+			validation.Tolerated = append(validation.Tolerated, invalid)
+		} else {
+			validation.Invalid = append(validation.Invalid, invalid)
+		}
+
+		for _, value := range invalids {
+			validation.addErrorRate(value.Value)
+		}
+	}
+	progress.add(len(tickets) - reported)
+
+	return validation, nil
+}
+
+// addErrorRate adds the value to the error rate, and keeps track of its overflow.
+func (v *Validation) addErrorRate(value int64) {
+	var wrapped bool
+	v.ErrorRate, wrapped = addInt64(v.ErrorRate, value)
+	v.ErrorRateOverflow = v.ErrorRateOverflow || wrapped
+}
+
+// InferOrdering works out the field held by each position of the tickets. All the tickets must be valid.
+func (s *Solver) InferOrdering(configs []Configuration, tickets []Ticket) ([]string, error) {
+	return s.InferOrderingContext(context.Background(), configs, tickets)
+}
+
+// InferOrderingContext is like InferOrdering, but gives up with the error of the context when the
+// context is done.
+func (s *Solver) InferOrderingContext(ctx context.Context, configs []Configuration, tickets []Ticket) ([]string, error) {
+	rules := compileRules(configs, s.rules)
+	candidates, err := orderingCandidates(rules, tickets, nil, s.workers())
+	if err != nil {
+		return nil, err
+	}
+
+	ordering, _, _, err := s.inferOrdering(ctx, candidates, ruleNames(rules))
+	return ordering, err
+}
+
+// orderingCandidates computes the candidate fields of each position from the valid tickets, narrowed by
+// the tolerated ones, see narrowCandidates. Up to workers positions are checked at the same time.
+func orderingCandidates(rules []Rule, tickets []Ticket, tolerated []InvalidTicket, workers int) ([][]bool, error) {
+	var positions int
+	switch {
+	case len(tickets) > 0:
+		positions = len(tickets[0].Values)
+	case len(tolerated) > 0:
+		positions = len(tolerated[0].Ticket.Values)
+	default:
+		return nil, errors.New("no valid ticket to infer the ordering from")
+	}
+
+	candidates := candidateMatrix(positions, tickets, rules, workers)
+	narrowCandidates(candidates, tolerated, rules)
+
+	return candidates, nil
+}
+
+// inferOrdering runs the ordering algorithm on the candidates. Besides the ordering, it tells for each
+// position whether its field is forced, being the same in all the orderings consistent with the tickets.
+// The wide notes are solved a component at a time, see inferComponents. The rounds of the elimination are
+// counted for Result.Rounds.
+func (s *Solver) inferOrdering(ctx context.Context, candidates [][]bool, fields []string) ([]string, []bool, int, error) {
+	progress := s.newProgress(ProgressOrder, len(candidates))
+
+	if len(candidates) >= minComponentPositions {
+		if comps := components(candidates, len(fields)); len(comps) > 1 {
+			ordering, forced, err := s.inferComponents(ctx, candidates, fields, comps, progress)
+			return ordering, forced, progress.rounds, err
+		}
+	}
+
+	ordering, forced, err := s.runAlgorithm(ctx, candidates, fields, progress)
+	return ordering, forced, progress.rounds, err
+}
+
+// runAlgorithm runs the ordering algorithm on all the candidates at once, adding the positions it assigns
+// to the progress.
+func (s *Solver) runAlgorithm(ctx context.Context, candidates [][]bool, fields []string, progress *progressCounter) ([]string, []bool, error) {
+	switch s.algorithm {
+	case AlgorithmMatching:
+		return getOrdering(ctx, candidates, fields, true, progress)
+	case AlgorithmElimination:
+		return getOrdering(ctx, candidates, fields, false, progress)
+	case AlgorithmBacktrack:
+		return backtrackOrdering(ctx, candidates, fields, progress)
+	default:
+		return nil, nil, fmt.Errorf("unknown ordering algorithm %q", s.algorithm)
+	}
+}
+
+// Orderings works out every ordering of the fields consistent with the valid tickets of the notes, up
+// to limit of them, or all of them when limit is 0. The tickets are picked like for SolveNotes. There is
+// a single ordering when the notes are well constrained, but their number grows like the factorial of
+// the number of positions that can't be told apart, hence the limit.
+func (s *Solver) Orderings(notes *Notes, limit int) ([][]string, error) {
+	return s.OrderingsContext(context.Background(), notes, limit)
+}
+
+// OrderingsContext is like Orderings, but gives up with the error of the context when the context is done.
+func (s *Solver) OrderingsContext(ctx context.Context, notes *Notes, limit int) ([][]string, error) {
+	// Only the validity of the tickets matters here.
+	validation, err := s.validate(ctx, notes, CollectFirst)
+	if err != nil {
+		return nil, err
+	}
+
+	tickets, err := s.orderingTickets(notes, validation)
+	if err != nil {
+		return nil, err
+	}
+	if s.dedup {
+		tickets, _ = dedupTickets(tickets)
+	}
+
+	rules := compileRules(notes.Configs, s.rules)
+	candidates, err := orderingCandidates(rules, tickets, validation.Tolerated, s.workers())
+	if err != nil {
+		return nil, err
+	}
+
+	return allOrderings(ctx, candidates, ruleNames(rules), limit)
+}
+
+// Solve reads the notes in the text format of the puzzle and solves both parts.
+func (s *Solver) Solve(r io.Reader) (Result, error) {
+	return s.SolveContext(context.Background(), r)
+}
+
+// SolveContext is like Solve, but gives up with the error of the context when the context is done.
+func (s *Solver) SolveContext(ctx context.Context, r io.Reader) (Result, error) {
+	start := time.Now()
+	notes, err := s.Parse(&contextReader{ctx: ctx, r: r})
+	if err != nil {
+		return Result{}, err
+	}
+	parseTime := time.Since(start)
+
+	result, err := s.SolveNotesContext(ctx, notes)
+	result.Timings.Parse = parseTime
+
+	return result, err
+}
+
+// orderingTickets returns the tickets the ordering is inferred from: the valid nearby tickets, and your
+// ticket depending on the policy.
+func (s *Solver) orderingTickets(notes *Notes, validation Validation) ([]Ticket, error) {
+	switch s.myTicket {
+	case MyTicketInclude, "":
+		return append([]Ticket{notes.MyTicket}, validation.Valid...), nil
+	case MyTicketExclude:
+		return validation.Valid, nil
+	case MyTicketValidate:
+		if len(invalidValues(notes.MyTicket, compileRules(notes.Configs, s.rules))) > 0 {
+			return validation.Valid, nil
+		}
+
+		return append([]Ticket{notes.MyTicket}, validation.Valid...), nil
+	default:
+		return nil, fmt.Errorf("unknown policy %q for your ticket", s.myTicket)
+	}
+}
+
+// groupOf gathers the values of your ticket whose field in the ordering starts with the prefix, in the order
+// of the ticket, and combines them. The fields whose value is unknown are left out of the combination.
+func groupOf(prefix string, ticket Ticket, ordering []string, aggregation Aggregation) Group {
+	group := Group{Prefix: prefix}
+
+	for fieldPos, value := range ticket.Values {
+		if fieldPos >= len(ordering) || !strings.HasPrefix(ordering[fieldPos], prefix) {
+			continue
+		}
+
+		if !ticket.Known(fieldPos) {
+			group.Unknown = append(group.Unknown, ordering[fieldPos])
+			continue
+		}
+
+		group.Fields = append(group.Fields, ordering[fieldPos])
+		group.Values = append(group.Values, value)
+	}
+	group.Value, group.Overflow = aggregation.aggregate(group.Values)
+
+	return group
+}
+
+// contextReader stops reading with the error of the context when the context is done.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+// Read implements the io.Reader interface.
+func (r *contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	return r.r.Read(p)
+}
+
+// SolveNotes solves both parts of the puzzle for notes that were already read.
+func (s *Solver) SolveNotes(notes *Notes) (Result, error) {
+	return s.SolveNotesContext(context.Background(), notes)
+}
+
+// SolveNotesContext is like SolveNotes, but gives up with the error of the context when the context is done.
+func (s *Solver) SolveNotesContext(ctx context.Context, notes *Notes) (Result, error) {
+	if len(notes.MyTicket.Values) == 0 {
+		return Result{}, &ParseError{Msg: "the notes do not contain your ticket"}
+	}
+
+	result := Result{}
+
+	start := time.Now()
+	validation, err := s.validate(ctx, notes, CollectAll)
+	if err != nil {
+		return Result{}, err
+	}
+
+	result.MyTicketInvalid, err = s.checkMyTicket(notes.MyTicket, compileRules(notes.Configs, s.rules))
+	if err != nil {
+		return Result{}, err
+	}
+	result.Timings.Validate = time.Since(start)
+
+	// Part 2, determine the fields ordering.
+	start = time.Now()
+	validTickets, err := s.orderingTickets(notes, validation)
+	if err != nil {
+		return Result{}, err
+	}
+	if s.dedup {
+		var counts []int
+		validTickets, counts = dedupTickets(validTickets)
+		result.Duplicates = duplicateStats(counts)
+	}
+	rules := compileRules(notes.Configs, s.rules)
+	fields := ruleNames(rules)
+	candidates, err := orderingCandidates(rules, validTickets, validation.Tolerated, s.workers())
+	if err != nil {
+		return Result{}, err
+	}
+
+	ordering, forced, rounds, err := s.inferOrdering(ctx, candidates, fields)
+	if err != nil {
+		// An invalid value of your ticket is the likely culprit, don't let the warning get lost.
+		if result.MyTicketInvalid != nil {
+			err = fmt.Errorf("%w (%v)", err, &InvalidMyTicketError{Values: result.MyTicketInvalid})
+		}
+
+		return Result{}, err
+	}
+	result.Positions = positionReports(candidates, fields, ordering, forced)
+	result.Violations = verifyOrdering(notes, validation, rules, ordering)
+	result.Timings.Order = time.Since(start)
+	result.Rounds = rounds
+
+	result.Part1, result.Part1Overflow = validation.ErrorRate, validation.ErrorRateOverflow
+	result.Ordering = ordering
+	result.ValidTickets = len(validation.Valid)
+	result.InvalidTickets = len(validation.Invalid)
+	result.ToleratedTickets = len(validation.Tolerated)
+	result.RaggedTickets = len(validation.Ragged)
+	if s.metrics {
+		result.Metrics = errorMetrics(notes, validation, rules)
+	}
+	if s.invalidDetails {
+		result.InvalidDetails = append(append([]InvalidTicket{}, validation.Invalid...), validation.Tolerated...)
+		sort.SliceStable(result.InvalidDetails, func(i, j int) bool {
+			return result.InvalidDetails[i].Index < result.InvalidDetails[j].Index
+		})
+	}
+
+	if err := s.answer(&result, notes.MyTicket, ordering); err != nil {
+		return Result{}, err
+	}
+
+	if s.bigInt {
+		result.Part1Big = new(big.Int)
+		for _, invalids := range [][]InvalidTicket{validation.Invalid, validation.Tolerated} {
+			for _, invalid := range invalids {
+				for _, value := range invalid.Values {
+					result.Part1Big.Add(result.Part1Big, big.NewInt(value.Value))
+				}
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// checkMyTicket checks your ticket against the rules as asked with WithMyTicketCheck. It returns the
+// invalid values to warn about, or an InvalidMyTicketError.
+func (s *Solver) checkMyTicket(myTicket Ticket, rules []Rule) ([]InvalidValue, error) {
+	switch s.myTicketCheck {
+	case MyTicketCheckWarn, "":
+		return explainValues(myTicket, rules), nil
+	case MyTicketCheckError:
+		if invalids := explainValues(myTicket, rules); invalids != nil {
+			return nil, &InvalidMyTicketError{Values: invalids}
+		}
+
+		return nil, nil
+	case MyTicketCheckOff:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unknown check %q for your ticket", s.myTicketCheck)
+	}
+}
+
+// answer fills part 2 and the groups of the result in from your ticket and the ordering.
+func (s *Solver) answer(result *Result, myTicket Ticket, ordering []string) error {
+	switch s.aggregation {
+	case AggregateProduct, AggregateSum, AggregateMin, AggregateMax, AggregateList:
+	default:
+		return fmt.Errorf("unknown aggregation %q", s.aggregation)
+	}
+	result.Aggregation = s.aggregation
+
+	part2 := groupOf(s.departurePrefix, myTicket, ordering, s.aggregation)
+	result.Part2, result.Part2Values, result.Part2Unknown = part2.Value, part2.Values, part2.Unknown
+	result.Part2Overflow = part2.Overflow
+	for _, prefix := range s.groups {
+		result.Groups = append(result.Groups, groupOf(prefix, myTicket, ordering, s.aggregation))
+	}
+
+	if s.bigInt {
+		result.Part2Big = s.aggregation.aggregateBig(result.Part2Values)
+		for idx := range result.Groups {
+			result.Groups[idx].BigValue = s.aggregation.aggregateBig(result.Groups[idx].Values)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,57 @@
+package ticket16
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+)
+
+// overflowingNotes hold two invalid values whose sum overflows int64.
+const overflowingNotes = `a: 0-1
+b: 0-1
+
+your ticket:
+0,1
+
+nearby tickets:
+9000000000000000000,1
+0,9000000000000000000
+1,0
+`
+
+func TestPart1Overflow(t *testing.T) {
+	want, _ := new(big.Int).SetString("18000000000000000000", 10)
+
+	solvers := map[string]func(solver *Solver) (Result, error){
+		"notes": func(solver *Solver) (Result, error) {
+			return solver.Solve(strings.NewReader(overflowingNotes))
+		},
+		"stream": func(solver *Solver) (Result, error) {
+			return solver.SolveStream(strings.NewReader(overflowingNotes))
+		},
+	}
+
+	for name, solve := range solvers {
+		t.Run(name, func(t *testing.T) {
+			result, err := solve(NewSolver(WithBigInt(true)))
+			if err != nil {
+				t.Fatalf("solve failed: %s", err)
+			}
+
+			if !result.Part1Overflow {
+				t.Errorf("part 1 = %d not flagged as an overflow", result.Part1)
+			}
+			if result.Part1Big == nil || result.Part1Big.Cmp(want) != 0 {
+				t.Errorf("part 1 with arbitrary precision = %v, want %s", result.Part1Big, want)
+			}
+		})
+	}
+
+	result, err := Solve(strings.NewReader(ExampleInput))
+	if err != nil {
+		t.Fatalf("Solve failed: %s", err)
+	}
+	if result.Part1Overflow {
+		t.Errorf("part 1 of the example = %d flagged as an overflow", result.Part1)
+	}
+}
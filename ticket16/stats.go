@@ -0,0 +1,90 @@
+package ticket16
+
+import "sort"
+
+// DefaultHistogramBins is the number of bins of the histograms of ValueStats used by the command line.
+const DefaultHistogramBins = 8
+
+// PositionStats sums up the values seen at a position of the tickets.
+type PositionStats struct {
+	Position int     // Position is the 0-based position in the tickets.
+	Count    int     // Count is the number of values seen.
+	Min      int64   // Min is the lowest value.
+	Max      int64   // Max is the highest value.
+	Mean     float64 // Mean is the average of the values.
+	Median   float64 // Median is the middle value, or the average of the two middle values.
+
+	// Histogram counts the values falling in each of its bins, which split the values from Min to Max
+	// in equal widths.
+	Histogram []int
+}
+
+// ValueStats computes the statistics of the values seen at each position of the tickets, with histograms
+// of the given number of bins. Tickets shorter than others only count for the positions they have, and
+// the unknown values don't count.
+func ValueStats(tickets []Ticket, bins int) []PositionStats {
+	width := 0
+	for _, ticket := range tickets {
+		if len(ticket.Values) > width {
+			width = len(ticket.Values)
+		}
+	}
+
+	stats := make([]PositionStats, width)
+	values := make([]int64, 0, len(tickets))
+
+	for fieldPos := range stats {
+		values = values[:0]
+		for _, ticket := range tickets {
+			if fieldPos < len(ticket.Values) && ticket.Known(fieldPos) {
+				values = append(values, ticket.Values[fieldPos])
+			}
+		}
+
+		stats[fieldPos] = positionStats(fieldPos, values, bins)
+	}
+
+	return stats
+}
+
+// positionStats computes the statistics of the values of a position. It sorts the values.
+func positionStats(fieldPos int, values []int64, bins int) PositionStats {
+	if bins < 1 {
+		bins = 1
+	}
+
+	stats := PositionStats{Position: fieldPos, Count: len(values), Histogram: make([]int, bins)}
+	if len(values) == 0 {
+		return stats
+	}
+
+	sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+	stats.Min, stats.Max = values[0], values[len(values)-1]
+
+	// Summing as floats keeps the mean of large values from overflowing.
+	sum := 0.0
+	for _, value := range values {
+		sum += float64(value)
+	}
+	stats.Mean = sum / float64(len(values))
+
+	middle := len(values) / 2
+	if len(values)%2 == 1 {
+		stats.Median = float64(values[middle])
+	} else {
+		stats.Median = float64(values[middle-1])/2 + float64(values[middle])/2
+	}
+
+	// The spread is computed on unsigned integers, so that it can't overflow either.
+	spread := float64(uint64(stats.Max)-uint64(stats.Min)) + 1
+	for _, value := range values {
+		// Rounding may push the highest values past the last bin.
+		bin := int(float64(uint64(value)-uint64(stats.Min)) / spread * float64(bins))
+		if bin >= bins {
+			bin = bins - 1
+		}
+		stats.Histogram[bin]++
+	}
+
+	return stats
+}
@@ -0,0 +1,211 @@
+package ticket16
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"time"
+)
+
+// NotesHandler receives the parts of the notes while they are being read. Any callback may be nil
+// when the consumer is not interested in that part. Returning an error from a callback stops the
+// parsing and the error is returned as is by StreamNotes.
+type NotesHandler struct {
+	// Configurations receives all the rules at once, as soon as the rules section is over. It is always
+	// called before any ticket is handed over, so the tickets can be validated as they arrive.
+	Configurations func(configs []Configuration) error
+
+	// MyTicket receives your ticket.
+	MyTicket func(ticket Ticket) error
+
+	// NearbyTicket receives each nearby ticket, in the order of the notes.
+	NearbyTicket func(ticket Ticket) error
+
+	// Source receives where the nearby ticket handed over next comes from, when the notes are read with
+	// ParseOptions.KeepSources.
+	Source func(source TicketSource)
+
+	// Warning receives the problems the parser recovered from in lenient mode.
+	Warning func(warning *ParseError)
+
+	// Skipped receives the malformed lines dropped because of ParseOptions.SkipMalformed, and the ragged
+	// tickets dropped because of RaggedSkip.
+	Skipped func(warning *ParseError)
+}
+
+// StreamNotes reads the notes from the reader and hands every part over to the handler as soon as
+// it is parsed, instead of keeping the whole document in memory. It reports the same problems as
+// ParseNotes.
+func StreamNotes(r io.Reader, opts ParseOptions, handler NotesHandler) error {
+	return streamNotes(r, opts, false, handler)
+}
+
+// SolveStream solves the notes read from the reader in a single pass, keeping neither the invalid nor the
+// valid nearby tickets in memory: the tickets are folded a chunk at a time, see WithChunkSize, the invalid
+// values being added to part 1 and the valid tickets only narrowing the fields each position may hold. Its
+// memory use doesn't depend on the number of tickets, which suits the notes too large for SolveNotes.
+//
+// The answers are the same as SolveNotes, but the steps needing the tickets are left out: the result has no
+// Violations, Duplicates or Metrics, whatever the options. The ragged tickets are only skipped by the parser,
+// see RaggedSkip.
+func (s *Solver) SolveStream(r io.Reader) (Result, error) {
+	return s.SolveStreamContext(context.Background(), r, NotesHandler{})
+}
+
+// SolveStreamContext is like SolveStream, but gives up with the error of the context when the context is
+// done. The callbacks of the handler are called too, once the solver is done with each part, e.g. to
+// report the problems the parser recovered from.
+func (s *Solver) SolveStreamContext(ctx context.Context, r io.Reader, handler NotesHandler) (Result, error) {
+	result, _, err := s.ResumeStream(ctx, r, handler, nil)
+	return result, err
+}
+
+// ResumeStream is like SolveStreamContext, but also returns the checkpoint of the solve, which lets the next
+// solve of the same notes, once new nearby tickets were appended to them, resume from it: the tickets
+// folded in the checkpoint are only hashed, to check that they didn't change, and the new ones alone are
+// folded. The checkpoint is ignored when its rules differ, and ResumeStream fails with
+// ErrCheckpointMismatch when the notes don't start with its tickets anymore, since these were not kept.
+func (s *Solver) ResumeStream(ctx context.Context, r io.Reader, handler NotesHandler, resume *Checkpoint) (Result, *Checkpoint, error) {
+	result := Result{}
+
+	var folder *chunkFolder
+	var myTicket Ticket
+	var rules string
+
+	// The values of the nearby tickets are hashed, a ticket at a time, for the checkpoint.
+	h := sha256.New()
+	var key []byte
+	seen, skip := 0, 0
+
+	start := time.Now()
+	err := StreamNotes(r, s.parse, NotesHandler{
+		Configurations: func(configs []Configuration) error {
+			folder = newChunkFolder(compileRules(configs, s.rules), s.maxInvalid, s.chunkSize)
+			folder.progress = s.newProgress(ProgressValidate, 0)
+
+			rules = s.rulesDigest(folder.rules)
+			if resume != nil && resume.Rules == rules {
+				skip = resume.Tickets
+			}
+			if s.bigInt {
+				folder.errorRateBig = new(big.Int)
+			}
+
+			return forward(handler.Configurations, configs)
+		},
+		MyTicket: func(ticket Ticket) error {
+			myTicket = ticket
+			return forward(handler.MyTicket, ticket)
+		},
+		NearbyTicket: func(ticket Ticket) error {
+			key = appendTicketKey(key[:0], ticket)
+			h.Write(key)
+			seen++
+
+			// The tickets of the checkpoint were folded already.
+			if seen <= skip {
+				if seen == skip {
+					if err := folder.resume(resume, h); err != nil {
+						return err
+					}
+				}
+
+				return forward(handler.NearbyTicket, ticket)
+			}
+
+			if len(folder.chunk) == 0 {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+			}
+
+			if err := folder.add(ticket); err != nil {
+				return err
+			}
+
+			return forward(handler.NearbyTicket, ticket)
+		},
+		Warning: handler.Warning,
+		Skipped: handler.Skipped,
+	})
+	if err == nil && folder != nil {
+		err = folder.fold()
+	}
+	if err == nil && seen < skip {
+		err = ErrCheckpointMismatch
+	}
+	if err != nil {
+		return Result{}, nil, err
+	}
+
+	if len(myTicket.Values) == 0 {
+		return Result{}, nil, &ParseError{Msg: "the notes do not contain your ticket"}
+	}
+
+	// Your ticket is left out of the checkpoint, it narrows the candidates of this solve alone.
+	checkpoint := folder.checkpoint(rules, seen, h)
+
+	result.MyTicketInvalid, err = s.checkMyTicket(myTicket, folder.rules)
+	if err != nil {
+		return Result{}, nil, err
+	}
+	result.Timings.Validate = time.Since(start)
+
+	// Part 2, your ticket narrows the candidates like the nearby ones, unless it is left out.
+	start = time.Now()
+	var include bool
+	switch s.myTicket {
+	case MyTicketInclude, "":
+		include = true
+	case MyTicketExclude:
+	case MyTicketValidate:
+		include = folder.index.validTicket(myTicket)
+	default:
+		return Result{}, nil, fmt.Errorf("unknown policy %q for your ticket", s.myTicket)
+	}
+	if include {
+		if err := folder.fit(myTicket); err != nil {
+			return Result{}, nil, err
+		}
+		folder.narrow(myTicket, nil)
+	}
+	if folder.candidates == nil {
+		return Result{}, nil, errors.New("no valid ticket to infer the ordering from")
+	}
+
+	candidates := folder.matrix()
+	fields := ruleNames(folder.rules)
+	ordering, forced, rounds, err := s.inferOrdering(ctx, candidates, fields)
+	if err != nil {
+		if result.MyTicketInvalid != nil {
+			err = fmt.Errorf("%w (%v)", err, &InvalidMyTicketError{Values: result.MyTicketInvalid})
+		}
+
+		return Result{}, nil, err
+	}
+	result.Positions = positionReports(candidates, fields, ordering, forced)
+	result.Ordering = ordering
+	result.Timings.Order = time.Since(start)
+	result.Rounds = rounds
+
+	result.Part1, result.Part1Overflow, result.Part1Big = folder.errorRate, folder.errorRateOverflow, folder.errorRateBig
+	result.ValidTickets, result.InvalidTickets, result.ToleratedTickets = folder.valid, folder.invalid, folder.tolerated
+
+	if err := s.answer(&result, myTicket, ordering); err != nil {
+		return Result{}, nil, err
+	}
+
+	return result, checkpoint, nil
+}
+
+// forward hands the part over to the callback, unless it is nil.
+func forward[P any](callback func(part P) error, part P) error {
+	if callback == nil {
+		return nil
+	}
+
+	return callback(part)
+}
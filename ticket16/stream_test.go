@@ -0,0 +1,122 @@
+package ticket16
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// notesText writes the notes in the text format, keeping the first tickets nearby tickets only.
+func notesText(t *testing.T, notes *Notes, tickets int) []byte {
+	t.Helper()
+
+	kept := *notes
+	kept.NearbyTickets = notes.NearbyTickets[:tickets]
+
+	var text bytes.Buffer
+	if err := WriteNotes(&text, &kept); err != nil {
+		t.Fatalf("WriteNotes failed: %s", err)
+	}
+
+	return text.Bytes()
+}
+
+func TestResumeStream(t *testing.T) {
+	generated, err := GenerateNotes(GenerateOptions{Fields: 8, Tickets: 50, InvalidRate: 0.2, Seed: 16})
+	if err != nil {
+		t.Fatalf("GenerateNotes failed: %s", err)
+	}
+	notes := generated.Notes
+	full := notesText(t, notes, len(notes.NearbyTickets))
+
+	// The chunks don't divide the tickets of the interrupted stream, nor of the whole one.
+	solver := NewSolver(WithChunkSize(7))
+	ctx := context.Background()
+
+	want, err := solver.SolveStream(bytes.NewReader(full))
+	if err != nil {
+		t.Fatalf("SolveStream failed: %s", err)
+	}
+
+	// The stream is interrupted after 20 nearby tickets, and its checkpoint saved like -state does.
+	_, saved, err := solver.ResumeStream(ctx, bytes.NewReader(notesText(t, notes, 20)), NotesHandler{}, nil)
+	if err != nil {
+		t.Fatalf("ResumeStream of the interrupted notes failed: %s", err)
+	}
+	data, err := json.Marshal(saved)
+	if err != nil {
+		t.Fatalf("json.Marshal of the checkpoint failed: %s", err)
+	}
+	load := func() *Checkpoint {
+		checkpoint := &Checkpoint{}
+		if err := json.Unmarshal(data, checkpoint); err != nil {
+			t.Fatalf("json.Unmarshal of the checkpoint failed: %s", err)
+		}
+
+		return checkpoint
+	}
+
+	t.Run("continue", func(t *testing.T) {
+		got, checkpoint, err := solver.ResumeStream(ctx, bytes.NewReader(full), NotesHandler{}, load())
+		if err != nil {
+			t.Fatalf("ResumeStream failed: %s", err)
+		}
+
+		if got.Part1 != want.Part1 || got.Part2 != want.Part2 || !reflect.DeepEqual(got.Ordering, want.Ordering) {
+			t.Errorf("part 1 %d, part 2 %d and ordering %v, want %d, %d and %v", got.Part1, got.Part2, got.Ordering, want.Part1, want.Part2, want.Ordering)
+		}
+		if got.ValidTickets != want.ValidTickets || got.InvalidTickets != want.InvalidTickets {
+			t.Errorf("%d valid and %d invalid tickets, want %d and %d", got.ValidTickets, got.InvalidTickets, want.ValidTickets, want.InvalidTickets)
+		}
+		if checkpoint.Tickets != len(notes.NearbyTickets) {
+			t.Errorf("checkpoint of %d tickets, want %d", checkpoint.Tickets, len(notes.NearbyTickets))
+		}
+	})
+
+	t.Run("skip the folded tickets", func(t *testing.T) {
+		// The error rate of the checkpoint is taken as it is, the tickets it folded being only hashed.
+		checkpoint := load()
+		checkpoint.ErrorRate += 1000
+
+		got, _, err := solver.ResumeStream(ctx, bytes.NewReader(full), NotesHandler{}, checkpoint)
+		if err != nil {
+			t.Fatalf("ResumeStream failed: %s", err)
+		}
+		if got.Part1 != want.Part1+1000 {
+			t.Errorf("part 1 = %d, want %d from the checkpoint", got.Part1, want.Part1+1000)
+		}
+	})
+
+	t.Run("changed tickets", func(t *testing.T) {
+		changed := *notes
+		changed.NearbyTickets = append([]Ticket{}, notes.NearbyTickets...)
+		changed.NearbyTickets[3] = Ticket{Values: append([]int64{}, notes.NearbyTickets[3].Values...)}
+		changed.NearbyTickets[3].Values[0]++
+
+		_, _, err := solver.ResumeStream(ctx, bytes.NewReader(notesText(t, &changed, len(changed.NearbyTickets))), NotesHandler{}, load())
+		if !errors.Is(err, ErrCheckpointMismatch) {
+			t.Errorf("ResumeStream error = %v, want %v", err, ErrCheckpointMismatch)
+		}
+	})
+
+	t.Run("fewer tickets", func(t *testing.T) {
+		_, _, err := solver.ResumeStream(ctx, bytes.NewReader(notesText(t, notes, 10)), NotesHandler{}, load())
+		if !errors.Is(err, ErrCheckpointMismatch) {
+			t.Errorf("ResumeStream error = %v, want %v", err, ErrCheckpointMismatch)
+		}
+	})
+
+	t.Run("other rules", func(t *testing.T) {
+		// The checkpoint of other rules is ignored, the notes being solved from scratch.
+		got, _, err := NewSolver(WithChunkSize(7), WithMaxInvalidValues(1)).ResumeStream(ctx, bytes.NewReader(full), NotesHandler{}, load())
+		if err != nil {
+			t.Fatalf("ResumeStream failed: %s", err)
+		}
+		if got.Part1 != want.Part1 {
+			t.Errorf("part 1 = %d, want %d", got.Part1, want.Part1)
+		}
+	})
+}
@@ -0,0 +1,43 @@
+package ticket16
+
+import "strings"
+
+// MarshalText implements the encoding.TextMarshaler interface with the rule line of the puzzle.
+func (c ConfigurationOf[T]) MarshalText() ([]byte, error) {
+	return []byte(formatConfiguration(c)), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface. It reads a rule line of the puzzle,
+// like the lines of the rules section of the notes. The bounds are read as 64-bit integers, then
+// converted to T.
+func (c *ConfigurationOf[T]) UnmarshalText(text []byte) error {
+	line := strings.TrimRight(string(text), "\r\n")
+
+	config, err := parseConfiguration(line, ParseOptions{})
+	if err != nil {
+		return atLine(err, 0, line)
+	}
+
+	*c = ConvertConfiguration[T](config)
+	return nil
+}
+
+// MarshalText implements the encoding.TextMarshaler interface with the ticket line of the puzzle.
+func (t TicketOf[T]) MarshalText() ([]byte, error) {
+	return []byte(formatTicket(t)), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface. It reads a ticket line of the puzzle,
+// like the lines of the ticket sections of the notes. The values are read as 64-bit integers, then
+// converted to T.
+func (t *TicketOf[T]) UnmarshalText(text []byte) error {
+	line := strings.TrimRight(string(text), "\r\n")
+
+	ticket, err := parseTicket(line, ParseOptions{})
+	if err != nil {
+		return atLine(err, 0, line)
+	}
+
+	*t = ConvertTicket[T](ticket)
+	return nil
+}
@@ -0,0 +1,131 @@
+// Package ticket16 solves Advent of code 2020 day 16: https://adventofcode.com/2020/day/16
+//
+// It reads the notes about the train tickets, finds the nearby tickets holding values that match no rule,
+// and works out which field each position of the tickets holds.
+package ticket16
+
+// YourTicket defines the indicator telling that the content after this is your Ticket details.
+const YourTicket = "your ticket"
+
+// NearbyTickets defines the indicator telling that the content after this is the nearby tickets details.
+const NearbyTickets = "nearby tickets"
+
+// Number is the constraint of the values the engine works with. The parsers produce int64 values, but the
+// validation and the ordering inference work with any of these types, see ValidateOf and InferOrderingOf.
+type Number interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 |
+		~float32 | ~float64
+}
+
+// ValidRangeOf stores the valid range (minimum and maximum Values). Both inclusive.
+// A range may be open-ended: when NoMin (or NoMax) is set, Min (or Max) is ignored and the range
+// has no lower (or upper) bound.
+type ValidRangeOf[T Number] struct {
+	Min   T
+	Max   T
+	NoMin bool
+	NoMax bool
+}
+
+// TicketOf stores the Ticket details.
+type TicketOf[T Number] struct {
+	Values []T
+
+	// Unknown tells which values couldn't be read, e.g. written as "?" on a partially scanned ticket, see
+	// ParseOptions.UnknownValues. Their Values are 0. It is nil when all the values are known.
+	Unknown []bool
+}
+
+// Known tells whether the value at the position of the ticket is known.
+func (t TicketOf[T]) Known(fieldPos int) bool {
+	return fieldPos >= len(t.Unknown) || !t.Unknown[fieldPos]
+}
+
+// ConfigurationOf stores the Ticket Configuration. A value matches the Configuration when it is inside
+// any of the Ranges and outside all the Exclusions.
+type ConfigurationOf[T Number] struct {
+	Field      string
+	Ranges     []ValidRangeOf[T]
+	Exclusions []ValidRangeOf[T]
+}
+
+// ValidRange is the ValidRangeOf produced by the parsers.
+type ValidRange = ValidRangeOf[int64]
+
+// Ticket is the TicketOf produced by the parsers.
+type Ticket = TicketOf[int64]
+
+// Configuration is the ConfigurationOf produced by the parsers.
+type Configuration = ConfigurationOf[int64]
+
+// ConvertTicket converts a parsed Ticket to a TicketOf another value type. Values that do not fit in T
+// are converted following the Go conversion rules.
+func ConvertTicket[T Number](ticket Ticket) TicketOf[T] {
+	values := make([]T, len(ticket.Values))
+	for idx, value := range ticket.Values {
+		values[idx] = T(value)
+	}
+
+	return TicketOf[T]{Values: values, Unknown: ticket.Unknown}
+}
+
+// ConvertConfiguration converts a parsed Configuration to a ConfigurationOf another value type.
+// Bounds that do not fit in T are converted following the Go conversion rules.
+func ConvertConfiguration[T Number](config Configuration) ConfigurationOf[T] {
+	convertRanges := func(ranges []ValidRange) []ValidRangeOf[T] {
+		if ranges == nil {
+			return nil
+		}
+
+		converted := make([]ValidRangeOf[T], len(ranges))
+		for idx, rng := range ranges {
+			converted[idx] = ValidRangeOf[T]{Min: T(rng.Min), Max: T(rng.Max), NoMin: rng.NoMin, NoMax: rng.NoMax}
+		}
+
+		return converted
+	}
+
+	return ConfigurationOf[T]{
+		Field:      config.Field,
+		Ranges:     convertRanges(config.Ranges),
+		Exclusions: convertRanges(config.Exclusions),
+	}
+}
+
+// FieldValueOf is a value of a ticket labelled with its field.
+type FieldValueOf[T Number] struct {
+	Field string
+	Value T
+}
+
+// FieldValue is the FieldValueOf of the parsed values.
+type FieldValue = FieldValueOf[int64]
+
+// Decode labels the values of the ticket with the fields of the ordering. Positions found in only one of
+// the ticket and the ordering are left out, and so are the unknown values.
+func (t TicketOf[T]) Decode(ordering []string) map[string]T {
+	decoded := make(map[string]T, len(ordering))
+	for _, fieldValue := range t.DecodeFields(ordering) {
+		decoded[fieldValue.Field] = fieldValue.Value
+	}
+
+	return decoded
+}
+
+// DecodeFields is like Decode, but keeps the values in the order of the ticket.
+func (t TicketOf[T]) DecodeFields(ordering []string) []FieldValueOf[T] {
+	size := len(t.Values)
+	if len(ordering) < size {
+		size = len(ordering)
+	}
+
+	decoded := make([]FieldValueOf[T], 0, size)
+	for idx := 0; idx < size; idx++ {
+		if t.Known(idx) {
+			decoded = append(decoded, FieldValueOf[T]{Field: ordering[idx], Value: t.Values[idx]})
+		}
+	}
+
+	return decoded
+}
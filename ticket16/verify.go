@@ -0,0 +1,78 @@
+package ticket16
+
+import "fmt"
+
+// Violation describes a value of a ticket kept to infer the ordering that the rule of its position doesn't
+// allow, even though some other rule does.
+type Violation struct {
+	Ticket   int    // Ticket is the 0-based index of the ticket among the nearby tickets, -1 for your ticket.
+	Position int    // Position is the 0-based position of the value in the ticket.
+	Field    string // Field is the field of the position in the ordering.
+	Value    int64  // Value is the value itself.
+}
+
+// String implements the fmt.Stringer interface.
+func (v Violation) String() string {
+	ticket := "your ticket"
+	if v.Ticket >= 0 {
+		ticket = fmt.Sprintf("nearby ticket %d", v.Ticket)
+	}
+
+	return fmt.Sprintf("%s holds %d at position %d, which %q doesn't allow", ticket, v.Value, v.Position, v.Field)
+}
+
+// verifyOrdering checks the values of your ticket and of the nearby tickets that were kept against the rule
+// of their position in the ordering. The invalid values of the tolerated tickets, which match no rule at
+// all, are left out. It returns nil when every value is allowed.
+func verifyOrdering(notes *Notes, validation Validation, rules []Rule, ordering []string) []Violation {
+	ruleOf := make(map[string]Rule, len(rules))
+	for _, rule := range rules {
+		if _, ok := ruleOf[rule.Name()]; !ok {
+			ruleOf[rule.Name()] = rule
+		}
+	}
+
+	rejected := make(map[int]bool, len(validation.Invalid)+len(validation.Ragged))
+	for _, invalid := range validation.Invalid {
+		rejected[invalid.Index] = true
+	}
+	for _, idx := range validation.Ragged {
+		rejected[idx] = true
+	}
+
+	var violations []Violation
+	check := func(ticketIdx int, ticket Ticket) {
+		for fieldPos, value := range ticket.Values {
+			if fieldPos >= len(ordering) {
+				break
+			}
+
+			rule, ok := ruleOf[ordering[fieldPos]]
+			if !ok || !ticket.Known(fieldPos) || rule.Matches(value) || !matchesAny(value, rules) {
+				continue
+			}
+
+			violations = append(violations, Violation{Ticket: ticketIdx, Position: fieldPos, Field: ordering[fieldPos], Value: value})
+		}
+	}
+
+	check(-1, notes.MyTicket)
+	for idx, ticket := range notes.NearbyTickets {
+		if !rejected[idx] {
+			check(idx, ticket)
+		}
+	}
+
+	return violations
+}
+
+// matchesAny checks whether any of the rules allows the value.
+func matchesAny(value int64, rules []Rule) bool {
+	for _, rule := range rules {
+		if rule.Matches(value) {
+			return true
+		}
+	}
+
+	return false
+}
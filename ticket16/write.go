@@ -0,0 +1,216 @@
+package ticket16
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// WriteNotes writes the notes in the notation used by the puzzle, which ParseNotes reads back.
+func WriteNotes(w io.Writer, notes *Notes) error {
+	bw := bufio.NewWriter(w)
+
+	for _, config := range notes.Configs {
+		bw.WriteString(formatConfiguration(config))
+		bw.WriteString("\n")
+	}
+
+	bw.WriteString("\n" + YourTicket + ":\n")
+	bw.WriteString(formatTicket(notes.MyTicket))
+	bw.WriteString("\n\n" + NearbyTickets + ":\n")
+
+	for _, ticket := range notes.NearbyTickets {
+		bw.WriteString(formatTicket(ticket))
+		bw.WriteString("\n")
+	}
+
+	return bw.Flush()
+}
+
+// formatConfiguration formats the configuration as a rule line, e.g. "class: 1-3 or 5-7 not 2-2".
+// The field name is quoted when it would not be parsed back as it is.
+func formatConfiguration[T Number](config ConfigurationOf[T]) string {
+	var sb strings.Builder
+
+	sb.WriteString(formatField(config.Field))
+	sb.WriteString(": ")
+	sb.WriteString(formatRanges(config.Ranges))
+
+	if len(config.Exclusions) > 0 {
+		sb.WriteString(" not ")
+		sb.WriteString(formatRanges(config.Exclusions))
+	}
+
+	return sb.String()
+}
+
+// formatField quotes the field name when parseConfiguration would read it differently otherwise: when it
+// contains a colon, starts with a double quote or a section header, or has surrounding whitespace.
+func formatField(field string) string {
+	needsQuotes := field == "" ||
+		strings.Contains(field, ":") ||
+		strings.HasPrefix(field, `"`) ||
+		strings.HasPrefix(field, YourTicket) ||
+		strings.HasPrefix(field, NearbyTickets) ||
+		strings.TrimSpace(field) != field
+
+	if needsQuotes {
+		return strconv.Quote(field)
+	}
+
+	return field
+}
+
+// formatRanges formats the ranges separated by " or ".
+func formatRanges[T Number](ranges []ValidRangeOf[T]) string {
+	formatted := make([]string, len(ranges))
+	for idx, rng := range ranges {
+		formatted[idx] = formatRange(rng)
+	}
+
+	return strings.Join(formatted, " or ")
+}
+
+// formatRange formats a range as "<min>-<max>", "<min>+" or "<=<max>". A range without any bound is
+// written as starting at the lowest value of T.
+func formatRange[T Number](rng ValidRangeOf[T]) string {
+	switch {
+	case rng.NoMin && rng.NoMax:
+		return formatNumber(lowest[T]()) + "+"
+	case rng.NoMax:
+		return formatNumber(rng.Min) + "+"
+	case rng.NoMin:
+		return "<=" + formatNumber(rng.Max)
+	default:
+		return formatNumber(rng.Min) + "-" + formatNumber(rng.Max)
+	}
+}
+
+// formatNumber formats a value or a range bound. Integers are written in decimal.
+func formatNumber[T Number](value T) string {
+	return fmt.Sprint(value)
+}
+
+// lowest returns the lowest value of T: zero for unsigned integers and minus infinity for floats.
+func lowest[T Number]() T {
+	var zero T
+	minusOne := zero - 1
+
+	switch {
+	case minusOne > zero:
+		return zero
+	case !isInteger[T]():
+		return T(math.Inf(-1))
+	}
+
+	// Double -1 until the next doubling overflows, which leaves the lowest signed integer.
+	value := minusOne
+	for value*2 < value {
+		value *= 2
+	}
+
+	return value
+}
+
+// formatTicket formats the ticket values separated by commas, the unknown ones as "?".
+func formatTicket[T Number](ticket TicketOf[T]) string {
+	formatted := make([]string, len(ticket.Values))
+	for idx, value := range ticket.Values {
+		formatted[idx] = "?"
+		if ticket.Known(idx) {
+			formatted[idx] = formatNumber(value)
+		}
+	}
+
+	return strings.Join(formatted, ",")
+}
+
+// String implements the fmt.Stringer interface with the notation of the puzzle, e.g. "1-3" or "5+".
+func (r ValidRangeOf[T]) String() string {
+	return formatRange(r)
+}
+
+// String implements the fmt.Stringer interface with the rule line of the puzzle, which ParseNotes reads
+// back, e.g. "class: 1-3 or 5-7".
+func (c ConfigurationOf[T]) String() string {
+	return formatConfiguration(c)
+}
+
+// String implements the fmt.Stringer interface with the ticket line of the puzzle, e.g. "7,1,14".
+func (t TicketOf[T]) String() string {
+	return formatTicket(t)
+}
+
+// String implements the fmt.Stringer interface with the answers and the ordering on a single line.
+func (r Result) String() string {
+	return fmt.Sprintf("part 1: %s, part 2: %s, ordering: %s", r.Part1Answer(), r.Part2Answer(), strings.Join(r.Ordering, ", "))
+}
+
+// Format pretty-prints the notes to the writer, like WriteNotes.
+func (n *Notes) Format(w io.Writer) error {
+	return WriteNotes(w, n)
+}
+
+// Format pretty-prints the result to the writer, over several lines: the answers, the groups, the ticket
+// counts, the invalid values of your ticket, the violations of the ordering, and how the field of each position was found.
+func (r Result) Format(w io.Writer) error {
+	return formatResult(w, r)
+}
+
+// ticketCounts describes the number of valid, tolerated and invalid nearby tickets. The tolerated tickets
+// are only mentioned when there are some.
+func ticketCounts(result Result) string {
+	if result.ToleratedTickets == 0 {
+		return fmt.Sprintf("%d valid, %d invalid", result.ValidTickets, result.InvalidTickets)
+	}
+
+	return fmt.Sprintf("%d valid, %d tolerated, %d invalid", result.ValidTickets, result.ToleratedTickets, result.InvalidTickets)
+}
+
+// formatResult writes the result over several lines, with a line for each position.
+func formatResult(w io.Writer, result Result) error {
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintf(bw, "part 1: %s\n", result.Part1Answer())
+	fmt.Fprintf(bw, "part 2: %s\n", result.Part2Answer())
+
+	for _, group := range result.Groups {
+		fmt.Fprintf(bw, "group %s: %s\n", strings.TrimSpace(group.Prefix), result.GroupAnswer(group))
+	}
+	fmt.Fprintf(bw, "tickets: %s\n", ticketCounts(result))
+	if result.Duplicates != nil {
+		fmt.Fprintf(bw, "duplicates: %s\n", result.Duplicates)
+	}
+
+	for _, invalid := range result.MyTicketInvalid {
+		fmt.Fprintf(bw, "your ticket: invalid %s\n", invalid)
+	}
+
+	for _, violation := range result.Violations {
+		fmt.Fprintf(bw, "violation: %s\n", violation)
+	}
+
+	if result.Metrics != nil {
+		fmt.Fprintf(bw, "discarded: %d\n", result.Metrics.Discarded)
+		for _, rule := range result.Metrics.Rules {
+			fmt.Fprintf(bw, "rule %s: %d accepted\n", rule.Field, rule.Accepted)
+		}
+		for _, position := range result.Metrics.Positions {
+			fmt.Fprintf(bw, "invalid at position %d: %d, summing to %d\n", position.Position, position.Invalid, position.Sum)
+		}
+	}
+
+	for _, position := range result.Positions {
+		how := "forced"
+		if !position.Forced {
+			how = "picked"
+		}
+
+		fmt.Fprintf(bw, "position %d: %s (%s among %s)\n", position.Position, position.Field, how, strings.Join(position.Candidates, ", "))
+	}
+
+	return bw.Flush()
+}
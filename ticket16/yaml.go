@@ -0,0 +1,48 @@
+package ticket16
+
+import (
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DecodeNotesYAML reads the notes from a YAML document of the form:
+//
+//	rules:
+//	  - field: class
+//	    ranges: [1-3, 5-7]
+//	    exclusions: [2-2]
+//	  - field: row
+//	    ranges:
+//	      - {min: 6, max: 11}
+//	      - {min: 33, max: 44}
+//	your_ticket: [7, 1, 14]
+//	nearby_tickets:
+//	  - [7, 3, 47]
+//	  - [40, 4, 50]
+//
+// Ranges may be written either as "min-max" scalars, read like the text format with the default options
+// (see DecodeNotes for others), or as min/max mappings holding at least one bound. The exclusions are optional.
+// Unknown keys are rejected so that typos don't go unnoticed.
+func DecodeNotesYAML(r io.Reader) (*Notes, error) {
+	return decodeNotesYAML(r, ParseOptions{})
+}
+
+// decodeNotesYAML is DecodeNotesYAML, with the options of the scalar ranges.
+func decodeNotesYAML(r io.Reader, opts ParseOptions) (*Notes, error) {
+	decoder := yaml.NewDecoder(r)
+	decoder.KnownFields(true)
+
+	var doc notesDocument
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("invalid YAML notes: %w", err)
+	}
+
+	notes, err := doc.toNotes(opts)
+	if err != nil {
+		return nil, fmt.Errorf("invalid YAML notes: %w", err)
+	}
+
+	return notes, nil
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/handracs2007/advent_of_code_2020_day16/ticket16"
+)
+
+// clearScreen moves the cursor home and clears the terminal, before drawing a frame of the tui.
+const clearScreen = "\x1b[H\x1b[2J"
+
+// eliminationView holds the frames of the tui: the candidates of each position before the first round of
+// the elimination, and the rounds narrowing them.
+type eliminationView struct {
+	fields     []string
+	ordering   []string
+	candidates [][]bool // candidates tells, by position, which fields the tickets allow there.
+	rounds     []ticket16.Round
+}
+
+// runTUI runs the tui subcommand: it solves the input, then draws the grid of the candidate fields of
+// each position as the rounds of the elimination narrow it, one round at a time or playing them.
+func runTUI(ctx context.Context, args []string) {
+	flags := flag.NewFlagSet("tui", flag.ExitOnError)
+	input := addInputFlags(flags)
+	validation := addValidationFlags(flags)
+	ordering := addOrderingFlags(flags)
+	delay := flags.Duration("delay", 500*time.Millisecond, "time between the rounds when playing them")
+	flags.Usage = commandUsage(flags, "tui [tui flags] [input file]", "Draws the candidate fields of each position as the rounds of the elimination narrow them.\nEnter shows the next round, \"p\" the previous one, \"a\" plays the rounds left and \"q\" quits.")
+
+	parseCommandFlags(flags, args, true)
+	if *ordering.algo == string(ticket16.AlgorithmBacktrack) {
+		usageError(flags.Usage, errors.New("the backtracking runs no rounds to draw"))
+	}
+	if *delay <= 0 {
+		usageError(flags.Usage, errors.New("-delay must be positive"))
+	}
+
+	notes, opts := loadInput(flags, input, interactiveInputPath(flags, input))
+
+	// The rounds never overlap, but those of the components of the wide notes come in any order.
+	var rounds []ticket16.Round
+	trace := ticket16.WithRoundTrace(func(round ticket16.Round) {
+		rounds = append(rounds, round)
+		logRound(round)
+	})
+	_, result := solveNotes(ctx, flags, notes, opts, validation, ordering, trace)
+	sort.Slice(rounds, func(i, j int) bool { return rounds[i].Number < rounds[j].Number })
+
+	view := newEliminationView(notes.Configs, result, rounds)
+	view.play(ctx, os.Stdin, os.Stdout, isTerminal(os.Stdout), *delay)
+}
+
+// newEliminationView builds the frames of the elimination that inferred the ordering of the result.
+func newEliminationView(configs []ticket16.Configuration, result ticket16.Result, rounds []ticket16.Round) *eliminationView {
+	columns := map[string]int{}
+	fields := make([]string, len(configs))
+	for idx, config := range configs {
+		fields[idx] = config.Field
+		columns[config.Field] = idx
+	}
+
+	candidates := make([][]bool, len(result.Positions))
+	for fieldPos, position := range result.Positions {
+		candidates[fieldPos] = make([]bool, len(fields))
+		for _, field := range position.Candidates {
+			candidates[fieldPos][columns[field]] = true
+		}
+	}
+
+	return &eliminationView{fields: fields, ordering: result.Ordering, candidates: candidates, rounds: rounds}
+}
+
+// play draws the frames, moving between them as told by the lines of r, until the end of r, a quit or the
+// end of the context. The terminal is cleared before each frame, the other outputs get them one after
+// the other.
+func (v *eliminationView) play(ctx context.Context, r io.Reader, w io.Writer, clear bool, delay time.Duration) {
+	lines := readLines(r)
+	frame := 0
+	playing := false
+
+	for {
+		if clear {
+			fmt.Fprint(w, clearScreen)
+		}
+		v.draw(w, frame)
+
+		if playing && frame < len(v.rounds) {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(delay):
+				frame++
+				continue
+			}
+		}
+		playing = false
+
+		var line string
+		select {
+		case <-ctx.Done():
+			return
+		case next, ok := <-lines:
+			if !ok {
+				return
+			}
+			line = strings.TrimSpace(next)
+		}
+
+		switch line {
+		case "", "n":
+			if frame < len(v.rounds) {
+				frame++
+			}
+		case "p":
+			if frame > 0 {
+				frame--
+			}
+		case "a":
+			playing = true
+		case "q":
+			return
+		}
+	}
+}
+
+// draw writes the frame showing the candidates left after the given number of rounds. The fields placed
+// are drawn "●", the last round's painted, the candidates left "·".
+func (v *eliminationView) draw(w io.Writer, frame int) {
+	placed := map[string]bool{}
+	latest := map[string]bool{}
+	for _, round := range v.rounds[:frame] {
+		for _, field := range round.Fields {
+			placed[field] = true
+		}
+	}
+	if frame > 0 {
+		round := v.rounds[frame-1]
+		for _, field := range round.Fields {
+			latest[field] = true
+		}
+
+		how := "placed"
+		if round.Matched {
+			how = "matched"
+		}
+		fmt.Fprintf(w, "round %d of %d %s %d field(s)\n\n", frame, len(v.rounds), how, len(round.Fields))
+	} else {
+		fmt.Fprintf(w, "before the %d round(s) of the elimination\n\n", len(v.rounds))
+	}
+
+	width := len(strconv.Itoa(len(v.fields)-1)) + 1
+	fmt.Fprintf(w, "%8s │", "position")
+	for idx := range v.fields {
+		fmt.Fprintf(w, "%*d", width, idx)
+	}
+	fmt.Fprintln(w)
+
+	left := 0
+	for fieldPos, row := range v.candidates {
+		var line strings.Builder
+		fmt.Fprintf(&line, "%8d │", fieldPos)
+
+		field := v.ordering[fieldPos]
+		for idx, candidate := range row {
+			cell := " "
+			switch {
+			case placed[field] && v.fields[idx] == field:
+				cell = "●"
+				if latest[field] {
+					cell = paint(w, colorHighlight, cell)
+				}
+			case !placed[field] && candidate && !placed[v.fields[idx]]:
+				cell = "·"
+			}
+
+			line.WriteString(strings.Repeat(" ", width-1) + cell)
+		}
+
+		if !placed[field] {
+			left++
+		}
+		fmt.Fprintln(w, line.String())
+	}
+
+	fmt.Fprintf(w, "\n%d position(s) left\n", left)
+	for idx, field := range v.fields {
+		fmt.Fprintf(w, "%*d %s\n", width, idx, paintField(w, field))
+	}
+	fmt.Fprintln(w, "\n[enter] next  [p] previous  [a] play  [q] quit")
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/handracs2007/advent_of_code_2020_day16/ticket16"
+)
+
+// Verbosity tells how much is logged to stderr besides the warnings and the errors, which always are. The
+// standard output only ever holds the answers and the reports asked for.
+type Verbosity int
+
+const (
+	// VerbosityDefault only logs the warnings and the errors.
+	VerbosityDefault Verbosity = iota
+	// VerbosityInfo also logs the summaries of the parsing, of the validation and of the ordering, with -v.
+	VerbosityInfo
+	// VerbosityDebug also logs each round of the elimination, with -vv.
+	VerbosityDebug
+)
+
+// verbosity is the verbosity asked on the command line.
+var verbosity = VerbosityDefault
+
+// setVerbosity sets the verbosity from the -v and -vv flags, the highest one winning.
+func setVerbosity(verbose bool, debug bool) {
+	switch {
+	case debug:
+		verbosity = VerbosityDebug
+	case verbose:
+		verbosity = VerbosityInfo
+	default:
+		verbosity = VerbosityDefault
+	}
+}
+
+// infof logs the message with -v or -vv.
+func infof(format string, args ...any) {
+	if verbosity >= VerbosityInfo {
+		log.Printf(format, args...)
+	}
+}
+
+// debugf logs the message with -vv.
+func debugf(format string, args ...any) {
+	if verbosity >= VerbosityDebug {
+		log.Printf(format, args...)
+	}
+}
+
+// logNotes logs what the notes hold.
+func logNotes(notes *ticket16.Notes) {
+	infof("Parsed %d rule(s), your ticket of %d value(s) and %d nearby ticket(s).", len(notes.Configs), len(notes.MyTicket.Values), len(notes.NearbyTickets))
+}
+
+// logSummary logs how the nearby tickets were validated and how the ordering was inferred.
+func logSummary(result ticket16.Result) {
+	tickets := result.ValidTickets + result.ToleratedTickets + result.InvalidTickets
+	if result.Timings.Parse > 0 {
+		infof("Parsed the notes in %s.", result.Timings.Parse.Round(time.Microsecond))
+	}
+	infof("Validated %d nearby ticket(s) in %s: %d valid, %d tolerated, %d invalid.", tickets, result.Timings.Validate.Round(time.Microsecond), result.ValidTickets, result.ToleratedTickets, result.InvalidTickets)
+	infof("Inferred the ordering of %d position(s) in %d round(s) in %s.", len(result.Ordering), result.Rounds, result.Timings.Order.Round(time.Microsecond))
+}
+
+// roundTrace returns the round trace logging each round of the elimination with -vv, nil otherwise.
+func roundTrace() func(round ticket16.Round) {
+	if verbosity < VerbosityDebug {
+		return nil
+	}
+
+	return logRound
+}
+
+// logRound logs a round of the elimination, for WithRoundTrace.
+func logRound(round ticket16.Round) {
+	how := "placed"
+	if round.Matched {
+		how = "matched"
+	}
+
+	fields := make([]string, len(round.Fields))
+	for idx, field := range round.Fields {
+		fields[idx] = strconv.Quote(field)
+	}
+
+	debugf("Round %d %s %s.", round.Number, how, strings.Join(fields, ", "))
+}